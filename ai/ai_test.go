@@ -196,3 +196,23 @@ func TestGetAis(t *testing.T) {
 	}
 
 }
+
+func TestPlayerDescriptor(t *testing.T) {
+	tests := []struct {
+		PlayerName string
+		Expected   string
+	}{
+		{"Dojo", "Dojo:0"},
+		{"Dojo_6", "Dojo:6"},
+		{"Dojo_12", "Dojo:12"},
+		{"Dojo_v2_3", "Dojo_v2:3"},
+		{"Dojo_v2", "Dojo_v2:0"},
+		{"My_Dojo_v2_10", "My_Dojo_v2:10"},
+	}
+
+	for _, test := range tests {
+		if descriptor := PlayerDescriptor(test.PlayerName); descriptor != test.Expected {
+			t.Error("Found", descriptor, "for", test.PlayerName, ", expected", test.Expected)
+		}
+	}
+}
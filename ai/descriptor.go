@@ -55,3 +55,18 @@ func DescriptorFromString(s string) Descriptor {
 
 	return descriptor
 }
+
+// PlayerDescriptor returns the descriptor that matches exactly the AI with the
+// given player name, e.g. Dojo_6 -> Dojo:6. Only the part after the last
+// underscore is the version, so that names with underscores are kept whole
+func PlayerDescriptor(playerName string) string {
+	i := strings.LastIndex(playerName, "_")
+
+	if i >= 0 {
+		if _, err := strconv.Atoi(playerName[i+1:]); err == nil {
+			return playerName[:i] + ":" + playerName[i+1:]
+		}
+	}
+
+	return playerName + ":0"
+}
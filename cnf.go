@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// defaultCnf is the game configuration file used by dojo
+const defaultCnf = "default.cnf"
+
+// cnfValue returns the value of a key of a game configuration file, where
+// every line has the format "<key> <value>"
+func cnfValue(fileName string, key string) (string, bool) {
+	content, err := ioutil.ReadFile(fileName)
+
+	if err != nil {
+		return "", false
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+
+		if len(fields) >= 2 && fields[0] == key {
+			return fields[1], true
+		}
+	}
+
+	return "", false
+}
+
+// cnfRounds returns the number of rounds of the game, or 0 if unknown
+func cnfRounds(fileName string) int {
+	value, ok := cnfValue(fileName, "nb_rounds")
+
+	if !ok {
+		return 0
+	}
+
+	rounds, _ := strconv.Atoi(value)
+
+	return rounds
+}
+
+// Cnf is the game configuration file used by a session, either default.cnf or
+// a temporary copy of it with some values overridden
+type Cnf struct {
+	File string
+	// Overrides contains the overridden values as "key=value"
+	Overrides []string
+}
+
+// cnfKey returns the key of the configuration file referred to by name, which
+// can omit the "nb_" prefix, e.g. "rounds" for "nb_rounds"
+func cnfKey(content string, name string) (string, bool) {
+	for _, key := range []string{name, "nb_" + name} {
+		for _, line := range strings.Split(content, "\n") {
+			if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == key {
+				return key, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// NewCnf returns the configuration that results from applying the
+// overrides, given as "key=value", to default.cnf. A temporary file is
+// generated when there are overrides, which must be removed with Remove.
+func NewCnf(overrides []string) (*Cnf, error) {
+	cnf := &Cnf{File: defaultCnf}
+
+	if len(overrides) == 0 {
+		return cnf, nil
+	}
+
+	content, err := ioutil.ReadFile(defaultCnf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+
+	for _, override := range overrides {
+		split := strings.SplitN(override, "=", 2)
+
+		if len(split) != 2 || len(strings.TrimSpace(split[1])) == 0 {
+			return nil, fmt.Errorf("invalid configuration override '%s', expected key=value", override)
+		}
+
+		key, ok := cnfKey(string(content), strings.TrimSpace(split[0]))
+
+		if !ok {
+			return nil, fmt.Errorf("unknown key '%s' in %s", split[0], defaultCnf)
+		}
+
+		values[key] = strings.TrimSpace(split[1])
+	}
+
+	lines := strings.Split(string(content), "\n")
+
+	for i, line := range lines {
+		fields := strings.Fields(line)
+
+		if len(fields) < 2 {
+			continue
+		}
+
+		if value, ok := values[fields[0]]; ok {
+			lines[i] = fields[0] + " " + value
+			cnf.Overrides = append(cnf.Overrides, fields[0]+"="+value)
+		}
+	}
+
+	file, err := ioutil.TempFile("", "dojo-*.cnf")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(strings.Join(lines, "\n")); err != nil {
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	cnf.File = file.Name()
+
+	return cnf, nil
+}
+
+// Remove removes the generated configuration file, if any
+func (cnf *Cnf) Remove() error {
+	if cnf.File == defaultCnf {
+		return nil
+	}
+
+	return os.Remove(cnf.File)
+}
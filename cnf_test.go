@@ -0,0 +1,105 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+const cnf = `Moria v1
+
+nb_players   4
+nb_rounds    200
+nb_cities 8
+  # comment with nb_rounds 5
+bonus_rounds 3
+`
+
+func TestNewCnf(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dojo-cnf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err := ioutil.WriteFile(defaultCnf, []byte(cnf), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Overrides []string
+		Expected  string
+		Applied   []string
+		Valid     bool
+	}{
+		{nil, cnf, nil, true},
+		{[]string{"nb_rounds=50"}, `Moria v1
+
+nb_players   4
+nb_rounds 50
+nb_cities 8
+  # comment with nb_rounds 5
+bonus_rounds 3
+`, []string{"nb_rounds=50"}, true},
+		{[]string{"rounds = 50", "cities=2", "bonus_rounds=1"}, `Moria v1
+
+nb_players   4
+nb_rounds 50
+nb_cities 2
+  # comment with nb_rounds 5
+bonus_rounds 1
+`, []string{"nb_rounds=50", "nb_cities=2", "bonus_rounds=1"}, true},
+		{[]string{"nb_rounds=50", "rounds=60"}, `Moria v1
+
+nb_players   4
+nb_rounds 60
+nb_cities 8
+  # comment with nb_rounds 5
+bonus_rounds 3
+`, []string{"nb_rounds=60"}, true},
+		{[]string{"speed=3"}, "", nil, false},
+		{[]string{"v1=3"}, "", nil, false},
+		{[]string{"nb_rounds"}, "", nil, false},
+		{[]string{"nb_rounds="}, "", nil, false},
+		{[]string{"=50"}, "", nil, false},
+	}
+
+	for _, test := range tests {
+		generated, err := NewCnf(test.Overrides)
+
+		if (err == nil) != test.Valid {
+			t.Error("Found error", err, "with the overrides", test.Overrides, ", expected valid", test.Valid)
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(generated.File)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != test.Expected {
+			t.Errorf("Found configuration\n%s\nwith the overrides %v, expected\n%s", content, test.Overrides, test.Expected)
+		}
+
+		if !reflect.DeepEqual(generated.Overrides, test.Applied) {
+			t.Error("Found overrides", generated.Overrides, ", expected", test.Applied)
+		}
+
+		if err := generated.Remove(); err != nil {
+			t.Error(err)
+		}
+
+		if _, err := os.Stat(defaultCnf); err != nil {
+			t.Fatal("Removed", defaultCnf)
+		}
+	}
+}
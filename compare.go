@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/albertsgrc/dojo/v2/ai"
+	"github.com/albertsgrc/dojo/v2/rating"
+	"github.com/albertsgrc/dojo/v2/utils"
+)
+
+// CompareOptions ...
+type CompareOptions struct {
+	// NumGames is the number of scenarios, each of them is played once by
+	// every candidate
+	NumGames int
+	Against  []string
+	Limits   utils.Limits
+	// MaxFailures is the number of failed games tolerated before the
+	// comparison is stopped, a negative value means no limit
+	MaxFailures   int
+	SelectionSeed int64
+	Cnf           *Cnf
+	// SPRT stops the comparison once the test decides whether the first
+	// candidate is better than the second one, nil means playing all the games
+	SPRT *SPRT
+}
+
+// Scenario is a seed, a set of opponents and the seat of the candidate
+type Scenario struct {
+	Seed      string
+	Opponents []string
+	Seat      int
+}
+
+// ScenarioResult contains the result of each candidate in a scenario
+type ScenarioResult struct {
+	Scenario Scenario
+	Scores   [2]int
+	Wins     [2]bool
+}
+
+// Comparison ...
+type Comparison struct {
+	Candidates [2]string
+	// Results contains the scenarios where the games of both candidates
+	// finished correctly
+	Results       []ScenarioResult
+	Failures      []GameResult
+	SelectionSeed int64
+	// SPRT is the sequential test of the comparison, nil if not requested
+	SPRT *SPRT
+}
+
+// ScoreDifferences returns the score of the first candidate minus the score
+// of the second one in every scenario
+func (comparison *Comparison) ScoreDifferences() []float64 {
+	differences := make([]float64, len(comparison.Results))
+
+	for i, result := range comparison.Results {
+		differences[i] = float64(result.Scores[0] - result.Scores[1])
+	}
+
+	return differences
+}
+
+// WinDifferences returns 1 for the scenarios won only by the first candidate,
+// -1 for the ones won only by the second one and 0 otherwise
+func (comparison *Comparison) WinDifferences() []float64 {
+	differences := make([]float64, len(comparison.Results))
+
+	for i, result := range comparison.Results {
+		if result.Wins[0] {
+			differences[i]++
+		}
+
+		if result.Wins[1] {
+			differences[i]--
+		}
+	}
+
+	return differences
+}
+
+// descriptors returns the players of the scenario with the candidate in its seat
+func (scenario Scenario) descriptors(candidate *ai.Ai) []string {
+	descriptors := make([]string, 0, len(scenario.Opponents)+1)
+	descriptors = append(descriptors, scenario.Opponents[:scenario.Seat]...)
+	descriptors = append(descriptors, candidate.Descriptor())
+
+	return append(descriptors, scenario.Opponents[scenario.Seat:]...)
+}
+
+type compareGame struct {
+	scenario  int
+	candidate int
+	res       gameResultError
+}
+
+// Compare plays every sampled scenario once with each candidate, so that
+// both of them face exactly the same seeds, opponents and seats
+func Compare(candidateDescriptors [2]string, options CompareOptions, onGameFinished func()) (*Comparison, error) {
+	var candidates [2]*ai.Ai
+	comparison := &Comparison{SelectionSeed: options.SelectionSeed, SPRT: options.SPRT}
+
+	for i, descriptor := range candidateDescriptors {
+		candidate, err := ai.GetAi(ai.DescriptorFromString(descriptor))
+
+		if err != nil {
+			return nil, err
+		}
+
+		candidates[i] = candidate
+		comparison.Candidates[i] = candidate.PlayerName()
+	}
+
+	if comparison.Candidates[0] == comparison.Candidates[1] {
+		return nil, fmt.Errorf("both candidates are %s", comparison.Candidates[0])
+	}
+
+	opponents, err := listPool(options.Against)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(opponents) == 0 {
+		return nil, fmt.Errorf("No AIs found for the opponents %v", options.Against)
+	}
+
+	scenarios := make([]Scenario, options.NumGames)
+	randGenSelection := rand.New(rand.NewSource(options.SelectionSeed))
+
+	for i := range scenarios {
+		scenario := &scenarios[i]
+
+		for opponent := 0; opponent < 3; opponent++ {
+			// Opponents are picked independently, so they may repeat
+			scenario.Opponents = append(scenario.Opponents, pick(randGenSelection, opponents, nil))
+		}
+
+		scenario.Seed = strconv.FormatInt(randGenSelection.Int63n(maxGameSeed), 10)
+		scenario.Seat = randGenSelection.Intn(4)
+	}
+
+	games := make(chan compareGame, 200)
+	done := make(chan struct{})
+
+	var stopped int32
+	var comparisonErr error
+
+	go func() {
+		// pending contains the result of the first finished game of every scenario
+		pending := make(map[int]GameResult)
+		failed := make(map[int]bool)
+
+		for game := range games {
+			if game.res.err != nil {
+				if comparisonErr == nil {
+					comparisonErr = game.res.err
+				}
+				atomic.StoreInt32(&stopped, 1)
+				continue
+			}
+
+			if game.res.result.Failed() {
+				comparison.Failures = append(comparison.Failures, game.res.result)
+				failed[game.scenario] = true
+
+				if options.MaxFailures >= 0 && len(comparison.Failures) > options.MaxFailures && comparisonErr == nil {
+					comparisonErr = fmt.Errorf("stopped the comparison because more than %d games failed", options.MaxFailures)
+					atomic.StoreInt32(&stopped, 1)
+				}
+			} else if other, ok := pending[game.scenario]; !ok {
+				pending[game.scenario] = game.res.result
+			} else if !failed[game.scenario] {
+				scenario := scenarios[game.scenario]
+				results := [2]GameResult{}
+				results[game.candidate] = game.res.result
+				results[1-game.candidate] = other
+
+				result := ScenarioResult{Scenario: scenario}
+				for i, gameResult := range results {
+					result.Scores[i] = gameResult.Scores[scenario.Seat]
+					result.Wins[i] = gameResult.Winner == scenario.Seat
+				}
+
+				comparison.Results = append(comparison.Results, result)
+
+				if options.SPRT != nil && options.SPRT.Add(rating.Outcome(result.Scores[0], result.Scores[1])) != SPRTContinue {
+					atomic.StoreInt32(&stopped, 1)
+				}
+			}
+
+			onGameFinished()
+		}
+
+		close(done)
+	}()
+
+	limit := newGameLimiter()
+
+	for i, scenario := range scenarios {
+		for candidate := range candidates {
+			if atomic.LoadInt32(&stopped) != 0 {
+				break
+			}
+
+			i, candidate := i, candidate
+			descriptors := scenario.descriptors(candidates[candidate])
+			runOptions := RunOptions{
+				Seed:   scenario.Seed,
+				Limits: options.Limits,
+				Cnf:    options.Cnf,
+				Origin: "compare",
+			}
+
+			limit.Execute(func() {
+				gameResult, err := Run(descriptors, runOptions)
+				games <- compareGame{i, candidate, gameResultError{gameResult, err}}
+			})
+		}
+	}
+
+	limit.Wait()
+	close(games)
+	<-done
+
+	return comparison, comparisonErr
+}
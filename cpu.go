@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/albertsgrc/dojo/v2/utils"
+	"github.com/jedib0t/go-pretty/text"
+)
+
+// cpuString formats the fraction of its time budget used by a player
+func cpuString(cpu float64) string {
+	if cpu < 0 {
+		return text.FgRed.Sprint("disqualified")
+	}
+
+	return fmt.Sprintf("cpu %.1f%%", 100*cpu)
+}
+
+// warnCPU warns about a player that got disqualified or used more than
+// warningPercentage of its time budget
+func warnCPU(player string, maxCPU float64, numDisqualified int, warningPercentage float64) {
+	if numDisqualified > 0 {
+		utils.Error(fmt.Sprintf("%s was disqualified for exceeding its time budget in %d games", player, numDisqualified))
+	}
+
+	if 100*maxCPU >= warningPercentage {
+		utils.Warning(fmt.Sprintf("%s used up to %.1f%% of its time budget", player, 100*maxCPU))
+	}
+}
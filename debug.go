@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/albertsgrc/dojo/v2/utils"
+)
+
+// sanitizerEnv makes the sanitizers abort on the first error so that the
+// crash can be caught by gdb
+var sanitizerEnv = []string{
+	"ASAN_OPTIONS=abort_on_error=1:detect_leaks=0",
+	"UBSAN_OPTIONS=print_stacktrace=1:halt_on_error=1:abort_on_error=1",
+}
+
+// sanitizerReport returns the error report of the sanitizers in the game
+// output, or an empty string if there is none
+func sanitizerReport(output string) string {
+	lines := strings.Split(output, "\n")
+
+	for i, line := range lines {
+		if strings.Contains(line, "==ERROR: ") || strings.Contains(line, "runtime error: ") {
+			return strings.TrimRight(strings.Join(lines[i:], "\n"), "\n")
+		}
+	}
+
+	return ""
+}
+
+// Backtrace reruns a crashed game with the same seed, seats and configuration
+// under gdb and returns the backtrace of the crash
+func Backtrace(gameResult GameResult, game string, cnfFile string, limits utils.Limits) (string, error) {
+	if _, err := exec.LookPath("gdb"); err != nil {
+		return "", fmt.Errorf("gdb not found")
+	}
+
+	file, err := ioutil.TempFile("", "dojo-*.res")
+	if err != nil {
+		return "", err
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+
+	args := append([]string{"-batch", "-ex", "run", "-ex", "bt", "--args", game},
+		gameArgs(gameResult.Players, gameResult.Seed, cnfFile, file.Name())...)
+
+	stdout, _, err := utils.Exec("gdb", utils.ExecOptions{Limits: limits, Env: sanitizerEnv}, args...)
+
+	if _, isExitError := err.(*exec.ExitError); err != nil && !isExitError {
+		return "", err
+	}
+
+	lines := strings.Split(stdout, "\n")
+
+	for i, line := range lines {
+		if strings.HasPrefix(line, "Program received signal") || strings.HasPrefix(line, "Program terminated") {
+			return strings.TrimRight(strings.Join(lines[i:], "\n"), "\n"), nil
+		}
+	}
+
+	return "", fmt.Errorf("the game did not crash under gdb")
+}
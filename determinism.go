@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/albertsgrc/dojo/v2/replay"
+	"github.com/albertsgrc/dojo/v2/utils"
+)
+
+// DeterminismCheck contains the result of playing the same game twice
+type DeterminismCheck struct {
+	Seed    int64
+	Players []string
+	Games   [2]GameResult
+	// Divergence describes where both games started to differ, it is empty if
+	// they are identical
+	Divergence string
+	// Error explains why the check could not be completed
+	Error string
+}
+
+// Deterministic ...
+func (check DeterminismCheck) Deterministic() bool {
+	return len(check.Error) == 0 && len(check.Divergence) == 0
+}
+
+func compareGames(check *DeterminismCheck, resFiles [2]string) {
+	for _, game := range check.Games {
+		if game.Failed() {
+			check.Error = fmt.Sprintf("game %s failed (%s)", game.ID, game.Outcome)
+			return
+		}
+	}
+
+	replays := [2]replay.Replay{}
+	for i, resFile := range resFiles {
+		gameReplay, err := replay.ParseFile(resFile)
+
+		if err != nil {
+			check.Error = err.Error()
+			return
+		}
+
+		replays[i] = gameReplay
+	}
+
+	if round, differ := replays[0].FirstDifference(replays[1]); differ {
+		if round < 0 {
+			check.Divergence = "before the first round"
+		} else if round < len(replays[0].Rounds) {
+			check.Divergence = fmt.Sprintf("round %d", replays[0].Rounds[round].Number)
+		} else {
+			check.Divergence = fmt.Sprintf("round %d", replays[1].Rounds[round].Number)
+		}
+	} else if !reflect.DeepEqual(check.Games[0].Scores, check.Games[1].Scores) {
+		check.Divergence = "final scores"
+	}
+}
+
+// CheckDeterminism plays every seed twice with exactly the same lineup and
+// seats, and compares the results of both games. The seats of the lineup are
+// rotated on every seed.
+func CheckDeterminism(playerDescriptors []string, numSeeds int, limits utils.Limits, onSeedFinished func()) ([]DeterminismCheck, error) {
+	lineup, err := resolveLineup(rand.New(rand.NewSource(timeSeed())), playerDescriptors)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := ioutil.TempDir("", "dojo-determinism")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	checks := make([]DeterminismCheck, numSeeds)
+	limit := newGameLimiter()
+
+	var mutex sync.Mutex
+	var checkErr error
+	var seedsDone sync.WaitGroup
+
+	for i := range checks {
+		check := &checks[i]
+		check.Seed = int64(i + 1)
+
+		descriptors := make([]string, len(lineup))
+		check.Players = make([]string, len(lineup))
+		for seat := range lineup {
+			player := lineup[(seat+i)%len(lineup)]
+			descriptors[seat] = player.Descriptor()
+			check.Players[seat] = player.PlayerName()
+		}
+
+		var resFiles [2]string
+		var wg sync.WaitGroup
+
+		for run := range resFiles {
+			resFiles[run] = filepath.Join(dir, fmt.Sprintf("seed-%d-%d.res", check.Seed, run))
+			wg.Add(1)
+
+			run := run
+			limit.Execute(func() {
+				defer wg.Done()
+
+				gameResult, err := Run(descriptors, RunOptions{
+					Seed:    strconv.FormatInt(check.Seed, 10),
+					Limits:  limits,
+					ResFile: resFiles[run],
+					Origin:  "check-determinism",
+				})
+
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				if err != nil && checkErr == nil {
+					checkErr = err
+				}
+
+				check.Games[run] = gameResult
+			})
+		}
+
+		seedsDone.Add(1)
+		go func() {
+			defer seedsDone.Done()
+
+			wg.Wait()
+			compareGames(check, resFiles)
+			onSeedFinished()
+		}()
+	}
+
+	limit.Wait()
+	seedsDone.Wait()
+
+	return checks, checkErr
+}
@@ -2,15 +2,17 @@ package main
 
 import (
 	"fmt"
-	"math"
 	"math/rand"
 	"runtime"
 	"sort"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/korovkin/limiter"
 
 	"github.com/albertsgrc/dojo/v2/ai"
+	"github.com/albertsgrc/dojo/v2/utils"
 )
 
 type aiResults struct {
@@ -18,7 +20,33 @@ type aiResults struct {
 	NumGames                int
 	Scores                  []int
 	NumWinsEvaluated        int
-	Elo                     int
+	TimelineSums            []float64
+	TimelineCounts          []int
+	CPUs                    []float64
+	NumDisqualified         int
+	Seats                   []SeatResult
+	HeadToHead              map[string]*HeadToHead
+}
+
+// HeadToHead contains the results of an AI against an opponent in the games
+// where both played
+type HeadToHead struct {
+	NumGames int
+	// NumAhead is the number of games the AI finished with a higher score
+	NumAhead int
+	NumTied  int
+}
+
+// WinPercentage returns the percentage of games the AI finished ahead of the
+// opponent, where ties count as half
+func (headToHead *HeadToHead) WinPercentage() float64 {
+	return 100 * (float64(headToHead.NumAhead) + float64(headToHead.NumTied)/2) / float64(headToHead.NumGames)
+}
+
+// SeatResult contains the results of an AI in the games it played from a seat
+type SeatResult struct {
+	Scores  []int
+	NumWins int
 }
 
 type EvaluationResult struct {
@@ -26,7 +54,141 @@ type EvaluationResult struct {
 	NumGamesAtPlaceOrBetter []int
 	Scores                  []int
 	NumWinsEvaluated        int
-	Elo                     int
+	Rating                  float64
+	// RatingDeviation is the uncertainty of the rating, 0 if the rating
+	// system does not provide it
+	RatingDeviation float64
+	// RatingInterval is the confidence interval of the rating, nil if the
+	// rating system does not provide it
+	RatingInterval []float64
+	// Timeline contains the mean score of the AI at the end of each round
+	Timeline []float64
+	// CPUs contains the fraction of its time budget that the AI used in every
+	// game where it was not disqualified
+	CPUs            []float64
+	NumDisqualified int
+	// Seats contains the results of the AI in each seat
+	Seats []SeatResult
+	// HeadToHead contains the results of the AI against every opponent
+	HeadToHead map[string]*HeadToHead
+}
+
+func (results *aiResults) addTimeline(timeline []int) {
+	for round, score := range timeline {
+		if round >= len(results.TimelineSums) {
+			results.TimelineSums = append(results.TimelineSums, 0)
+			results.TimelineCounts = append(results.TimelineCounts, 0)
+		}
+
+		results.TimelineSums[round] += float64(score)
+		results.TimelineCounts[round]++
+	}
+}
+
+func (results *aiResults) addHeadToHead(opponent string, score int, opponentScore int) {
+	headToHead, ok := results.HeadToHead[opponent]
+	if !ok {
+		headToHead = new(HeadToHead)
+		results.HeadToHead[opponent] = headToHead
+	}
+
+	headToHead.NumGames++
+
+	if score > opponentScore {
+		headToHead.NumAhead++
+	} else if score == opponentScore {
+		headToHead.NumTied++
+	}
+}
+
+func (results *aiResults) meanTimeline() []float64 {
+	timeline := make([]float64, len(results.TimelineSums))
+
+	for round, sum := range results.TimelineSums {
+		timeline[round] = sum / float64(results.TimelineCounts[round])
+	}
+
+	return timeline
+}
+
+// Evaluation ...
+type Evaluation struct {
+	Ranking []*EvaluationResult
+	// Failures contains the games that did not finish correctly, which are not
+	// taken into account in the ranking
+	Failures []GameResult
+	// SelectionSeed reproduces the evaluation when passed to --selection-seed
+	SelectionSeed int64
+	// NumReused is the number of games from previous sessions included in the ranking
+	NumReused int
+	// NumOutdated is the number of games from previous sessions that were not
+	// included because the source of some AI, the game or its configuration changed
+	NumOutdated int
+	// SPRT is the sequential test of the evaluated AI, nil if not requested
+	SPRT *SPRT
+	// NumResumed is the number of games of the session played before it was resumed
+	NumResumed int
+	// Interrupted is set when the evaluation was interrupted before playing all its games
+	Interrupted bool
+}
+
+// EvaluateOptions ...
+type EvaluateOptions struct {
+	NumGames int
+	Against  []string
+	Limits   utils.Limits
+	// MaxFailures is the number of failed games tolerated before the
+	// evaluation is stopped, a negative value means no limit
+	MaxFailures int
+	// SelectionSeed determines the players, seats and seeds of every game
+	SelectionSeed int64
+	// KeepLogs stores the lines written by each player in every game record
+	KeepLogs bool
+	// Cnf is the game configuration, default.cnf when nil
+	Cnf *Cnf
+	// RotateSeats plays every sampled lineup and seed once in each rotation
+	// of the seats, instead of shuffling the seats of every game
+	RotateSeats bool
+	// Reuse includes in the ranking the compatible games played before
+	Reuse bool
+	// Rating is the name of the rating system, plackett-luce by default
+	Rating string
+	// SPRT stops the evaluation once the test decides whether the evaluated
+	// AI is better than the rest, nil means playing all the games
+	SPRT *SPRT
+	// Scheduler is the name of the scheduler that picks the players of every
+	// game, random by default
+	Scheduler string
+	// Pools contains the entries of the named pools of the lineup template,
+	// such as Dummy*3
+	Pools map[string][]string
+	// Lineup is the template of the players of every game, with one pool
+	// name, me or against per seat. Empty means using the scheduler
+	Lineup []string
+	// Duration is the time budget of the evaluation, once spent no more games
+	// are started. When set, NumGames is ignored
+	Duration time.Duration
+	// Interrupt stops scheduling new games when closed. The games that fail
+	// afterwards are discarded, since they may have been killed by a second
+	// interruption
+	Interrupt <-chan struct{} `json:"-"`
+}
+
+// gamesPerSample returns the number of games played for every sampled lineup and seed
+func (options EvaluateOptions) gamesPerSample() int {
+	if options.RotateSeats {
+		return 4
+	}
+
+	return 1
+}
+
+// TotalGames returns the number of games of the evaluation, which is rounded
+// up to play all the rotations of every sample
+func (options EvaluateOptions) TotalGames() int {
+	perSample := options.gamesPerSample()
+
+	return (options.NumGames + perSample - 1) / perSample * perSample
 }
 
 type gameResultError struct {
@@ -34,72 +196,77 @@ type gameResultError struct {
 	err    error
 }
 
-// ByEloDescending ...
-type ByEloDescending []*EvaluationResult
+type evaluationGame struct {
+	// index is the position of the game in the schedule
+	index int
+	res   gameResultError
+}
+
+// ByRatingDescending ...
+type ByRatingDescending []*EvaluationResult
 
-func (a ByEloDescending) Len() int {
+func (a ByRatingDescending) Len() int {
 	return len(a)
 }
 
-func (a ByEloDescending) Less(i, j int) bool {
-	return a[i].Elo > a[j].Elo
+func (a ByRatingDescending) Less(i, j int) bool {
+	return a[i].Rating > a[j].Rating
 }
 
-func (a ByEloDescending) Swap(i, j int) {
+func (a ByRatingDescending) Swap(i, j int) {
 	a[i], a[j] = a[j], a[i]
 }
 
-func winProbability(elo1, elo2 int) float64 {
-	return 1.0 / (1 + math.Pow(10, float64(elo1-elo2)/400.0))
+// newGameLimiter limits the number of games played concurrently to the number of CPUs
+func newGameLimiter() *limiter.ConcurrencyLimiter {
+	return limiter.NewConcurrencyLimiter(runtime.NumCPU())
 }
 
-func updateElos(winner, loser *aiResults) {
-	pLoser := winProbability(winner.Elo, loser.Elo)
-	pWinner := winProbability(loser.Elo, winner.Elo)
+// runGame schedules the games of a sample, which is a lineup and a seed
+// played once or in every rotation of the seats. The lineup and the seeds are
+// always drawn so that the schedule only depends on the selection seed, but
+// the games already played are skipped
+func runGame(randGenSelection *rand.Rand, scheduler Scheduler, sample int, played map[int]string, options EvaluateOptions, stopped *int32, limit *limiter.ConcurrencyLimiter, games chan evaluationGame) {
+	descriptors := scheduler.Next(randGenSelection)
 
-	winner.Elo += int(11 * (1 - pWinner))
-	loser.Elo += int(11 * (0 - pLoser))
-}
+	seed := strconv.FormatInt(randGenSelection.Int63n(maxGameSeed), 10)
+	selectionSeed := randGenSelection.Int63()
 
-func runGame(randGenTime *rand.Rand, ais []*ai.Ai, limit *limiter.ConcurrencyLimiter, gameResults chan gameResultError) {
-	descriptors := []string{}
+	for rotation := 0; rotation < options.gamesPerSample(); rotation++ {
+		index := sample*options.gamesPerSample() + rotation
+		if _, ok := played[index]; ok {
+			continue
+		}
 
-	playerSet := make(map[string]bool)
+		rotated := make([]string, len(descriptors))
+		for seat := range rotated {
+			rotated[seat] = descriptors[(seat+rotation)%len(descriptors)]
+		}
 
-	for player := 0; player < 4; player++ {
-		descriptor := ais[randGenTime.Intn(len(ais))].Descriptor()
-		if player < len(ais) {
-			_, ok := playerSet[descriptor]
-			for ok {
-				descriptor = ais[randGenTime.Intn(len(ais))].Descriptor()
-				_, ok = playerSet[descriptor]
-			}
+		runOptions := RunOptions{
+			Seed:          seed,
+			SelectionSeed: selectionSeed,
+			Shuffle:       !options.RotateSeats,
+			Limits:        options.Limits,
+			KeepLogs:      options.KeepLogs,
+			Cnf:           options.Cnf,
+			Origin:        "evaluate",
 		}
 
-		playerSet[descriptor] = true
-		descriptors = append(descriptors, descriptor)
+		limit.Execute(func() {
+			// The evaluation may have stopped while waiting for a free slot
+			if atomic.LoadInt32(stopped) == 1 {
+				return
+			}
 
+			gameResult, err := Run(rotated, runOptions)
+			games <- evaluationGame{index, gameResultError{gameResult, err}}
+		})
 	}
-
-	limit.Execute(func() {
-		gameResult, err := Run(descriptors, "time", true, false)
-		gameResults <- gameResultError{gameResult, err}
-	})
 }
 
-func processResult(evaluatedAi *ai.Ai, res gameResultError, aiToResults map[string]*aiResults, errChan chan error, onGameFinished func()) {
-	if res.err != nil {
-		errChan <- res.err
-	}
-
-	gameResult := res.result
-
-	scores := make(map[string]int)
-	for i, player := range gameResult.Players {
-		if gameResult.Scores[i] >= scores[player] {
-			scores[player] = gameResult.Scores[i]
-		}
-	}
+func processResult(evaluatedPlayer string, gameResult GameResult, aiToResults map[string]*aiResults, ratings RatingSystem) {
+	scores := gameScores(gameResult)
 
 	for player, score := range scores {
 		var evaluations *aiResults
@@ -108,99 +275,376 @@ func processResult(evaluatedAi *ai.Ai, res gameResultError, aiToResults map[stri
 			evaluations = new(aiResults)
 			evaluations.Scores = make([]int, 0)
 			evaluations.NumGamesAtPlaceOrBetter = make([]int, 3)
-			evaluations.Elo = 1500
+			evaluations.Seats = make([]SeatResult, len(gameResult.Players))
+			evaluations.HeadToHead = make(map[string]*HeadToHead)
 		}
 
 		evaluations.Scores = append(evaluations.Scores, score)
 		aiToResults[player] = evaluations
 	}
 
+	for seat, player := range gameResult.Players {
+		seatResult := &aiToResults[player].Seats[seat]
+		seatResult.Scores = append(seatResult.Scores, gameResult.Scores[seat])
+
+		if seat == gameResult.Winner {
+			seatResult.NumWins++
+		}
+	}
+
+	for i, timeline := range gameResult.Timeline {
+		if i < len(gameResult.Players) {
+			aiToResults[gameResult.Players[i]].addTimeline(timeline)
+		}
+	}
+
+	for i, cpu := range gameResult.CPU {
+		if i < len(gameResult.Players) {
+			results := aiToResults[gameResult.Players[i]]
+
+			if cpu < 0 {
+				results.NumDisqualified++
+			} else {
+				results.CPUs = append(results.CPUs, cpu)
+			}
+		}
+	}
+
 	winner := gameResult.Players[gameResult.Winner]
 
-	if winner == evaluatedAi.PlayerName() {
+	if winner == evaluatedPlayer {
 		for player := range scores {
 			aiToResults[player].NumWinsEvaluated++
 		}
 	}
 
-	players := gameResult.PlayersSorted
-	p0 := aiToResults[players[0]]
-	p1 := aiToResults[players[1]]
-	p2 := aiToResults[players[2]]
-	p3 := aiToResults[players[3]]
-
-	updateElos(p0, p1)
-	updateElos(p0, p2)
-	updateElos(p0, p3)
-	updateElos(p1, p2)
-	updateElos(p1, p3)
-	updateElos(p2, p3)
+	players, playerScores := ratedScores(scores)
+	ratings.Update(players, playerScores)
 
+	// Players appearing more than once count their best place
+	placed := make(map[string]bool)
 	for i, player := range gameResult.PlayersSorted {
+		if placed[player] {
+			continue
+		}
+		placed[player] = true
+
 		for j := i; j < 3; j++ {
 			aiToResults[player].NumGamesAtPlaceOrBetter[j]++
 		}
 	}
 
-	onGameFinished()
+	for player, score := range scores {
+		for opponent, opponentScore := range scores {
+			if opponent != player {
+				aiToResults[player].addHeadToHead(opponent, score, opponentScore)
+			}
+		}
+	}
 }
 
-// Evaluate ...
-func Evaluate(evaluatedAi *ai.Ai, numGames int, againstDescriptors []string, onGameFinished func()) ([]*EvaluationResult, error) {
+// addGameResult adds a game to the results of an evaluation, and returns
+// whether the evaluation should stop, either because the SPRT decided or
+// because too many games failed, in which case it also returns an error
+func addGameResult(evaluation *Evaluation, evaluatedPlayer string, gameResult GameResult, aiToResults map[string]*aiResults, ratings RatingSystem, options EvaluateOptions) (bool, error) {
+	if gameResult.Failed() {
+		evaluation.Failures = append(evaluation.Failures, gameResult)
+
+		if options.MaxFailures >= 0 && len(evaluation.Failures) == options.MaxFailures+1 {
+			return true, fmt.Errorf("stopped the evaluation because more than %d games failed", options.MaxFailures)
+		}
+
+		return false, nil
+	}
+
+	processResult(evaluatedPlayer, gameResult, aiToResults, ratings)
+
+	if result, ok := gameOutcome(evaluatedPlayer, gameResult); ok && options.SPRT != nil {
+		if options.SPRT.Add(result) != SPRTContinue {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// rankResults returns the results of every AI sorted by rating
+func rankResults(aiToResults map[string]*aiResults, ratings RatingSystem) []*EvaluationResult {
+	evaluationResults := make([]*EvaluationResult, 0)
+	for player, aiResults := range aiToResults {
+
+		evaluationResult := new(EvaluationResult)
+		evaluationResult.Player = player
+		evaluationResult.NumGamesAtPlaceOrBetter = aiResults.NumGamesAtPlaceOrBetter
+		evaluationResult.Scores = aiResults.Scores
+		evaluationResult.NumWinsEvaluated = aiResults.NumWinsEvaluated
+		evaluationResult.Rating, evaluationResult.RatingDeviation = ratings.Rating(player)
+		if intervals, ok := ratings.(intervalRatingSystem); ok {
+			low, high := intervals.Interval(player)
+			evaluationResult.RatingInterval = []float64{low, high}
+		}
+		evaluationResult.Timeline = aiResults.meanTimeline()
+		evaluationResult.CPUs = aiResults.CPUs
+		evaluationResult.NumDisqualified = aiResults.NumDisqualified
+		evaluationResult.Seats = aiResults.Seats
+		evaluationResult.HeadToHead = aiResults.HeadToHead
+		evaluationResults = append(evaluationResults, evaluationResult)
+	}
+
+	sort.Sort(ByRatingDescending(evaluationResults))
+
+	return evaluationResults
+}
+
+// evaluationScheduler returns the scheduler of the evaluation and the pools
+// it picks the players from
+func evaluationScheduler(evaluatedAi *ai.Ai, pool []poolAi, options EvaluateOptions) (Scheduler, [][]poolAi, error) {
+	schedulerName := options.Scheduler
+	if len(schedulerName) == 0 {
+		schedulerName = defaultScheduler
+	}
+
+	if len(options.Lineup) == 0 {
+		scheduler, err := newScheduler(schedulerName, evaluatedAi, pool)
+		return scheduler, [][]poolAi{pool}, err
+	}
+
+	if schedulerName != defaultScheduler {
+		return nil, nil, fmt.Errorf("a lineup template can't be combined with the %s scheduler", schedulerName)
+	}
+
+	againstPool, err := listPool(options.Against)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pools := map[string][]poolAi{againstSlot: againstPool}
+	for name, entries := range options.Pools {
+		if pools[name], err = listPool(entries); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	scheduler, err := newTemplateScheduler(evaluatedAi, options.Lineup, pools)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	usedPools := make([][]poolAi, 0, len(options.Lineup))
+	for _, slot := range options.Lineup {
+		usedPools = append(usedPools, pools[slot])
+	}
+
+	return scheduler, usedPools, nil
+}
+
+// Evaluate plays the games of an evaluation and ranks the AIs. When session
+// is not nil every finished game is recorded in it, and the games it already
+// contains are not played again
+func Evaluate(evaluatedAi *ai.Ai, options EvaluateOptions, session *Session, onGameFinished func()) (*Evaluation, error) {
+	againstDescriptors := options.Against
 	numDescriptors := len(againstDescriptors)
 
 	if numDescriptors == 0 {
 		return nil, fmt.Errorf("evaluate received no against descriptors")
 	}
 
-	againstDescriptorsValue := make([]ai.Descriptor, len(againstDescriptors)+1)
-	againstDescriptorsValue[0] = ai.DescriptorFromString(evaluatedAi.Descriptor())
-	for i, descriptor := range againstDescriptors {
-		againstDescriptorsValue[i+1] = ai.DescriptorFromString(descriptor)
+	pool, err := listPool(append([]string{evaluatedAi.Descriptor()}, againstDescriptors...))
+	if err != nil {
+		return nil, err
 	}
 
-	ais := ai.List(againstDescriptorsValue...)
+	scheduler, pools, err := evaluationScheduler(evaluatedAi, pool, options)
+	if err != nil {
+		return nil, err
+	}
+
+	// ais contains every AI that can play in the evaluation
+	ais := []*ai.Ai{evaluatedAi}
+	listed := map[string]bool{evaluatedAi.PlayerName(): true}
+	for _, pool := range pools {
+		for _, poolAi := range pool {
+			if !listed[poolAi.ai.PlayerName()] {
+				listed[poolAi.ai.PlayerName()] = true
+				ais = append(ais, poolAi.ai)
+			}
+		}
+	}
+
+	ratingName := options.Rating
+	if len(ratingName) == 0 {
+		ratingName = defaultRating
+	}
+
+	ratings, err := newRatingSystem(ratingName)
+	if err != nil {
+		return nil, err
+	}
 
 	aiToResults := make(map[string]*aiResults)
-	gameResults := make(chan gameResultError, 200)
-	errChan := make(chan error)
+	evaluation := &Evaluation{SelectionSeed: options.SelectionSeed, SPRT: options.SPRT}
+
+	played := make(map[int]string)
+	if session != nil {
+		played = session.Games
+	}
+
+	if options.Reuse {
+		cnfFile := defaultCnf
+		if options.Cnf != nil {
+			cnfFile = options.Cnf.File
+		}
+
+		reusable, numOutdated, err := reusableGames(ais, cnfFile, "evaluate")
+		if err != nil {
+			return nil, err
+		}
+
+		sessionGames := make(map[string]bool)
+		for _, id := range played {
+			sessionGames[id] = true
+		}
+
+		// The games to reuse are found again when resuming
+		if session != nil {
+			session.Reused = nil
+		}
+
+		for _, gameResult := range reusable {
+			if !sessionGames[gameResult.ID] {
+				processResult(evaluatedAi.PlayerName(), gameResult, aiToResults, ratings)
+				evaluation.NumReused++
+
+				if session != nil {
+					session.Reused = append(session.Reused, gameResult.ID)
+				}
+			}
+		}
+
+		evaluation.NumOutdated = numOutdated
+	}
+
+	// stopped is set to stop scheduling new games
+	var stopped int32
+	var evaluationErr error
+
+	addResult := func(gameResult GameResult) {
+		stop, err := addGameResult(evaluation, evaluatedAi.PlayerName(), gameResult, aiToResults, ratings, options)
+
+		if err != nil && evaluationErr == nil {
+			evaluationErr = err
+		}
+
+		if stop {
+			atomic.StoreInt32(&stopped, 1)
+		}
+	}
+
+	// resumed contains the games played before the session was resumed, the
+	// collector records the new ones in played while the games are scheduled
+	resumed := make(map[int]string)
+
+	if session != nil {
+		for _, index := range session.PlayedIndices() {
+			gameResult, err := loadGameResult(played[index])
+			if err != nil {
+				return nil, fmt.Errorf("could not resume session %s: %v", session.ID, err)
+			}
+
+			resumed[index] = played[index]
+			addResult(gameResult)
+			onGameFinished()
+		}
+
+		evaluation.NumResumed = len(resumed)
+	}
+
+	games := make(chan evaluationGame, 200)
+	done := make(chan struct{})
+
+	var interrupted int32
+
+	start := time.Now()
+	var elapsedBefore time.Duration
+	if session != nil {
+		elapsedBefore = session.Elapsed
+	}
+
+	go func() {
+		select {
+		case <-options.Interrupt:
+			atomic.StoreInt32(&interrupted, 1)
+			atomic.StoreInt32(&stopped, 1)
+		case <-done:
+		}
+	}()
 
 	go func() {
-		for res := range gameResults {
-			processResult(evaluatedAi, res, aiToResults, errChan, onGameFinished)
+		for game := range games {
+			res := game.res
+
+			if atomic.LoadInt32(&interrupted) == 1 && (res.err != nil || res.result.Failed()) {
+				continue
+			}
+
+			if res.err != nil {
+				if evaluationErr == nil {
+					evaluationErr = res.err
+				}
+				atomic.StoreInt32(&stopped, 1)
+				continue
+			}
+
+			addResult(res.result)
+
+			if session != nil {
+				played[game.index] = res.result.ID
+				session.Order = append(session.Order, game.index)
+				session.Elapsed = elapsedBefore + time.Since(start)
+				if err := session.Save(); err != nil && evaluationErr == nil {
+					evaluationErr = err
+					atomic.StoreInt32(&stopped, 1)
+				}
+			}
+
+			onGameFinished()
 		}
 
-		close(errChan)
+		close(done)
 	}()
 
-	limit := limiter.NewConcurrencyLimiter(runtime.NumCPU())
-	s := rand.NewSource(time.Now().UnixNano() / 1000)
-	randGenTime := rand.New(s)
+	if options.Duration > 0 {
+		// The time spent before the session was resumed counts too
+		budget := options.Duration
+		if session != nil {
+			budget -= session.Elapsed
+		}
 
-	for game := 0; game < numGames; game++ {
-		runGame(randGenTime, ais, limit, gameResults)
+		timer := time.AfterFunc(budget, func() {
+			atomic.StoreInt32(&stopped, 1)
+		})
+		defer timer.Stop()
 	}
 
-	limit.Wait()
-	close(gameResults)
+	limit := newGameLimiter()
+	s := rand.NewSource(options.SelectionSeed)
+	randGenSelection := rand.New(s)
 
-	for err := range errChan {
-		return nil, err
+	for sample := 0; (options.Duration > 0 || sample*options.gamesPerSample() < options.NumGames) && atomic.LoadInt32(&stopped) == 0; sample++ {
+		runGame(randGenSelection, scheduler, sample, resumed, options, &stopped, limit, games)
 	}
 
-	evaluationResults := make([]*EvaluationResult, 0)
-	for player, aiResults := range aiToResults {
+	limit.Wait()
+	close(games)
+	<-done
 
-		evaluationResult := new(EvaluationResult)
-		evaluationResult.Player = player
-		evaluationResult.NumGamesAtPlaceOrBetter = aiResults.NumGamesAtPlaceOrBetter
-		evaluationResult.Scores = aiResults.Scores
-		evaluationResult.NumWinsEvaluated = aiResults.NumWinsEvaluated
-		evaluationResult.Elo = aiResults.Elo
-		evaluationResults = append(evaluationResults, evaluationResult)
+	evaluation.Interrupted = atomic.LoadInt32(&interrupted) == 1
+
+	if session != nil {
+		session.Elapsed = elapsedBefore + time.Since(start)
 	}
 
-	sort.Sort(ByEloDescending(evaluationResults))
+	evaluation.Ranking = rankResults(aiToResults, ratings)
 
-	return evaluationResults, nil
+	return evaluation, evaluationErr
 }
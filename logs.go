@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const logsFileName = "logs.json"
+
+// RoundLog contains the lines a player wrote during its turn in a round
+type RoundLog struct {
+	Round int
+	Lines []string
+}
+
+// PlayerLog ...
+type PlayerLog struct {
+	Player string
+	Seat   int
+	Rounds []RoundLog
+}
+
+var playerMarkerRegexp = regexp.MustCompile(`^info:\s+(start|end) player (\d+)\s*$`)
+
+// splitLogs splits the game output into the lines written by each player in
+// each round, using the round and player turn markers of the game
+func splitLogs(output string, players []string) []PlayerLog {
+	logs := make([]PlayerLog, len(players))
+	for seat, player := range players {
+		logs[seat] = PlayerLog{Player: player, Seat: seat, Rounds: make([]RoundLog, 0)}
+	}
+
+	round := 0
+	seat := -1
+
+	for _, line := range strings.Split(output, "\n") {
+		if match := startRoundRegexp.FindStringSubmatch(line); match != nil && strings.HasPrefix(line, "info:") {
+			round, _ = strconv.Atoi(match[1])
+			continue
+		}
+
+		if match := playerMarkerRegexp.FindStringSubmatch(line); match != nil {
+			seat = -1
+			if match[1] == "start" {
+				seat, _ = strconv.Atoi(match[2])
+			}
+			continue
+		}
+
+		if seat < 0 || seat >= len(logs) {
+			continue
+		}
+
+		rounds := &logs[seat].Rounds
+		if len(*rounds) == 0 || (*rounds)[len(*rounds)-1].Round != round {
+			*rounds = append(*rounds, RoundLog{Round: round, Lines: make([]string, 0)})
+		}
+
+		(*rounds)[len(*rounds)-1].Lines = append((*rounds)[len(*rounds)-1].Lines, line)
+	}
+
+	return logs
+}
+
+func saveLogs(id string, logs []PlayerLog) error {
+	content, err := json.Marshal(logs)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(gameDir(id), logsFileName), content, 0644)
+}
+
+func loadLogs(id string) ([]PlayerLog, error) {
+	var logs []PlayerLog
+
+	content, err := ioutil.ReadFile(filepath.Join(gameDir(id), logsFileName))
+
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("the logs of game %s were not kept, rerun it with: dojo rerun %s", id, id)
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(content, &logs)
+
+	return logs, err
+}
+
+// matchesPlayer checks if the log belongs to the player given by its name or seat number
+func (log PlayerLog) matchesPlayer(player string) bool {
+	if len(player) == 0 || log.Player == player {
+		return true
+	}
+
+	seat, err := strconv.Atoi(player)
+
+	return err == nil && seat == log.Seat
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const gameOutput = `info: seed 1
+info: loading game
+info: start round 1
+info:     start player 0
+thinking in round 1
+info:     end player 0
+info:     start player 1
+info:     end player 1
+info:     start player 2
+end player 0
+start round 7
+info:     end player 2
+info: start round 2
+info:     start player 0
+first line
+second line
+info:     end player 0
+info:     start player 2
+last words
+`
+
+func TestSplitLogs(t *testing.T) {
+	logs := splitLogs(gameOutput, []string{"Dojo", "Dummy", "Null"})
+
+	expected := []PlayerLog{
+		{"Dojo", 0, []RoundLog{{1, []string{"thinking in round 1"}}, {2, []string{"first line", "second line"}}}},
+		{"Dummy", 1, []RoundLog{}},
+		{"Null", 2, []RoundLog{{1, []string{"end player 0", "start round 7"}}, {2, []string{"last words", ""}}}},
+	}
+
+	if !reflect.DeepEqual(logs, expected) {
+		t.Error("Found logs", logs, ", expected", expected)
+	}
+}
+
+func TestRunningPlayer(t *testing.T) {
+	tests := []struct {
+		Output   string
+		Expected int
+	}{
+		{"", -1},
+		{gameOutput, 2},
+		{"info:     start player 1\ninfo:     end player 1\n", -1},
+		{"info:     start player 3\nend player 3\n", 3},
+		{"info:     start player 0\nstart player 2\n", 0},
+	}
+
+	for _, test := range tests {
+		if player := runningPlayer(test.Output); player != test.Expected {
+			t.Error("Found running player", player, "in", test.Output, ", expected", test.Expected)
+		}
+	}
+}
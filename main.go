@@ -1,9 +1,18 @@
 package main
 
 import (
+	"encoding/csv"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime/debug"
+	"sort"
 	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/jedib0t/go-pretty/progress"
@@ -20,6 +29,16 @@ import (
 	"github.com/urfave/cli/v2/altsrc"
 )
 
+// version is the version of dojo, set by make with -ldflags "-X main.version=..."
+// or taken from the module when built with go install
+var version = "dev"
+
+func init() {
+	if info, ok := debug.ReadBuildInfo(); ok && version == "dev" && len(info.Main.Version) > 0 && info.Main.Version != "(devel)" {
+		version = info.Main.Version
+	}
+}
+
 func list(c *cli.Context) error {
 	descriptors := make([]ai.Descriptor, c.NArg())
 
@@ -92,13 +111,22 @@ func newVersion(c *cli.Context) error {
 	return nil
 }
 
-func run(c *cli.Context) error {
+func gameLimits(c *cli.Context) utils.Limits {
+	return utils.Limits{
+		Timeout:    c.Duration("timeout"),
+		MemoryMB:   c.Int("memory-limit"),
+		CPUSeconds: c.Int("cpu-limit"),
+	}
+}
+
+// playGame compiles and runs a single game, printing its result
+func playGame(c *cli.Context, playerDescriptors []string, options RunOptions) (GameResult, error) {
 	pw := progress.NewWriter()
 	pw.SetTrackerLength(25)
-	pw.ShowOverallTracker(false)
+	pw.ShowOverallTracker(true)
 	pw.ShowTime(true)
 	pw.ShowTracker(false)
-	pw.ShowValue(false)
+	pw.ShowValue(true)
 	pw.SetMessageWidth(24)
 	pw.SetNumTrackersExpected(1)
 	pw.SetStyle(progress.StyleDefault)
@@ -106,32 +134,299 @@ func run(c *cli.Context) error {
 	pw.SetUpdateFrequency(time.Millisecond * 100)
 	pw.Style().Colors = progress.StyleColorsExample
 
-	if !c.Bool("print-output") {
+	if !options.PrintOutput {
 		go pw.Render()
 	}
 
+	debug := c.Bool("debug")
+	targets := []string{}
+
+	if debug {
+		targets = append(targets, c.String("debug-target"))
+		options.Game = c.String("debug-game")
+		options.Env = sanitizerEnv
+		// AddressSanitizer reserves terabytes of virtual memory, which any
+		// ulimit -v would make it abort on
+		options.Limits.MemoryMB = 0
+	}
+
+	if options.Cnf == nil {
+		options.Cnf = &Cnf{File: defaultCnf}
+	}
+
 	fmt.Printf("Compiling ... ")
-	err := utils.Compile()
+	err := utils.Compile(targets...)
 	fmt.Printf("done\n")
 
 	if err != nil {
-		return err
+		return GameResult{}, err
+	}
+
+	trackerRun := progress.Tracker{Message: "Running game", Total: int64(cnfRounds(options.Cnf.File))}
+	options.OnRound = func(round int) {
+		trackerRun.SetValue(int64(round - 1))
 	}
 
-	trackerRun := progress.Tracker{Message: "Running game"}
 	pw.AppendTracker(&trackerRun)
-	gameResult, errRun := Run(c.StringSlice("players"), c.String("seed"), c.Bool("shuffle"), c.Bool("print-output"))
+	gameResult, errRun := Run(playerDescriptors, options)
+
+	if errRun == nil && debug && (gameResult.Outcome == OutcomeCrash || gameResult.Outcome == OutcomePlayerAborted) {
+		trackerRun.Message = "Rerunning under gdb"
+
+		backtrace, err := Backtrace(gameResult, options.Game, options.Cnf.File, options.Limits)
+
+		if err != nil {
+			gameResult.Backtrace = "could not get the backtrace: " + err.Error()
+		} else {
+			gameResult.Backtrace = backtrace
+		}
+
+		errRun = saveGameResult(gameResult)
+	}
+
 	trackerRun.MarkAsDone()
 
 	pw.Stop()
 
 	if errRun != nil {
-		return errRun
+		return gameResult, errRun
 	}
 
 	fmt.Println()
+
+	if gameResult.Failed() && !options.PrintOutput && len(gameResult.Error) > 0 {
+		fmt.Println(gameResult.Error)
+		fmt.Println()
+	}
+
 	fmt.Print(gameResult)
 
+	if len(gameResult.Backtrace) > 0 {
+		fmt.Println()
+		fmt.Println(text.Bold.Sprint("Backtrace"))
+		fmt.Println(gameResult.Backtrace)
+		fmt.Println()
+	}
+
+	for i, cpu := range gameResult.CPU {
+		if cpu < 0 {
+			warnCPU(gameResult.Players[i], 0, 1, c.Float64("cpu-warning"))
+		} else {
+			warnCPU(gameResult.Players[i], cpu, 0, c.Float64("cpu-warning"))
+		}
+	}
+
+	if c.Bool("timeline") && !gameResult.Failed() {
+		series := make([]utils.Series, len(gameResult.Timeline))
+
+		for i, timeline := range gameResult.Timeline {
+			series[i].Name = fmt.Sprintf("%s (%d)", gameResult.Players[i], i)
+			for _, score := range timeline {
+				series[i].Values = append(series[i].Values, float64(score))
+			}
+		}
+
+		fmt.Println()
+		fmt.Print(utils.Chart(series, 72, 16))
+	}
+
+	if gameResult.Failed() {
+		return gameResult, fmt.Errorf("the game failed (%s), reproduce it with: %s", gameResult.Outcome, reproduceCommand(gameResult))
+	}
+
+	return gameResult, nil
+}
+
+// sessionCnf generates the game configuration of the session from the
+// overrides of default.cnf
+func sessionCnf(overrides []string) (*Cnf, error) {
+	cnf, err := NewCnf(overrides)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cnf.Overrides) > 0 {
+		fmt.Printf("Overriding %s: %s\n", defaultCnf, text.Bold.Sprint(strings.Join(cnf.Overrides, " ")))
+	}
+
+	return cnf, nil
+}
+
+func viewOptions(c *cli.Context) ViewOptions {
+	return ViewOptions{
+		Viewer:      c.String("viewer"),
+		ViewerFile:  c.String("viewer-file"),
+		ViewerParam: c.String("viewer-param"),
+		Port:        c.Int("port"),
+		Open:        !c.Bool("no-open"),
+	}
+}
+
+func run(c *cli.Context) error {
+	selectionSeed, err := parseSeed(c.String("selection-seed"))
+
+	if err != nil {
+		return err
+	}
+
+	cnf, err := sessionCnf(c.StringSlice("cnf-set"))
+
+	if err != nil {
+		return err
+	}
+	defer cnf.Remove()
+
+	gameResult, err := playGame(c, c.StringSlice("players"), RunOptions{
+		Seed:          c.String("seed"),
+		SelectionSeed: selectionSeed,
+		Shuffle:       c.Bool("shuffle"),
+		PrintOutput:   c.Bool("print-output"),
+		Limits:        gameLimits(c),
+		ResFile:       "default.res",
+		KeepReplay:    true,
+		KeepLogs:      true,
+		Cnf:           cnf,
+		Origin:        "run",
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("view") {
+		fmt.Println()
+		return View(filepath.Join(gameDir(gameResult.ID), replayFileName), viewOptions(c))
+	}
+
+	return nil
+}
+
+func logs(c *cli.Context) error {
+	id := "last"
+	if c.NArg() > 0 {
+		id = c.Args().First()
+	}
+
+	gameResult, err := loadGameResult(id)
+
+	if err != nil {
+		return err
+	}
+
+	playerLogs, err := loadLogs(gameResult.ID)
+
+	if err != nil {
+		return err
+	}
+
+	var grep *regexp.Regexp
+	if pattern := c.String("grep"); len(pattern) > 0 {
+		if grep, err = regexp.Compile(pattern); err != nil {
+			return err
+		}
+	}
+
+	round := c.Int("round")
+
+	for _, playerLog := range playerLogs {
+		if !playerLog.matchesPlayer(c.String("player")) {
+			continue
+		}
+
+		if grep == nil {
+			fmt.Println(text.Colors{text.Bold, text.BgBlack, text.FgRed}.Sprintf("%s (seat %d)", playerLog.Player, playerLog.Seat))
+		}
+
+		for _, roundLog := range playerLog.Rounds {
+			if round > 0 && roundLog.Round != round {
+				continue
+			}
+
+			if grep == nil {
+				fmt.Println(text.Faint.Sprintf("── round %d", roundLog.Round))
+			}
+
+			for _, line := range roundLog.Lines {
+				if grep == nil {
+					fmt.Println(line)
+				} else if grep.MatchString(line) {
+					prefix := text.Faint.Sprintf("%s:%d round %d:", playerLog.Player, playerLog.Seat, roundLog.Round)
+					fmt.Println(prefix, grep.ReplaceAllStringFunc(line, func(match string) string {
+						return text.Colors{text.Bold, text.FgRed}.Sprint(match)
+					}))
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func view(c *cli.Context) error {
+	replay, err := replayFile(c.Args().First())
+
+	if err != nil {
+		return err
+	}
+
+	return View(replay, viewOptions(c))
+}
+
+func rerun(c *cli.Context) error {
+	var original GameResult
+	var err error
+
+	if fromResult := c.String("from-result"); len(fromResult) > 0 {
+		original, err = loadGameResultFile(fromResult)
+	} else if c.NArg() > 0 {
+		original, err = loadGameResult(c.Args().First())
+	} else {
+		return fmt.Errorf("missing game id, use 'last' to rerun the last game played")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	descriptors, err := rerunDescriptors(original, c.StringSlice("substitute"))
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Rerunning game %s with seed %s\n", text.Bold.Sprint(original.ID), text.Bold.Sprint(original.Seed))
+
+	cnf, err := sessionCnf(original.CnfOverrides)
+
+	if err != nil {
+		return err
+	}
+	defer cnf.Remove()
+
+	gameResult, err := playGame(c, descriptors, RunOptions{
+		Seed:        original.Seed,
+		PrintOutput: c.Bool("print-output"),
+		Limits:      gameLimits(c),
+		ResFile:     "default.res",
+		KeepReplay:  true,
+		KeepLogs:    true,
+		Cnf:         cnf,
+		Origin:      "rerun",
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if len(c.StringSlice("substitute")) == 0 && !original.Failed() {
+		if reflect.DeepEqual(original.Scores, gameResult.Scores) {
+			fmt.Println("\n✅ Same result as the original game")
+		} else {
+			fmt.Printf("\n⚠️  The original game ended with scores %v\n", original.Scores)
+		}
+	}
+
 	return nil
 }
 
@@ -162,16 +457,108 @@ func fw(x float64) string {
 	return styler.Sprintf("%.2f", x)
 }
 
-func evaluate(c *cli.Context) error {
+// evaluateOptions builds the options of a new evaluation from the flags
+func evaluateOptions(c *cli.Context) (EvaluateOptions, error) {
+	selectionSeed, err := parseSeed(c.String("selection-seed"))
+
+	if err != nil {
+		return EvaluateOptions{}, err
+	}
+
+	var sprt *SPRT
+	if len(c.String("sprt")) > 0 {
+		if sprt, err = parseSPRT(c.String("sprt")); err != nil {
+			return EvaluateOptions{}, err
+		}
+	}
+
+	pools, err := parsePools(c.StringSlice("pool"))
+	if err != nil {
+		return EvaluateOptions{}, err
+	}
+
+	var lineup []string
+	if len(c.String("lineup")) > 0 {
+		lineup = strings.Split(c.String("lineup"), ",")
+		for i := range lineup {
+			lineup[i] = strings.TrimSpace(lineup[i])
+		}
+	} else if len(pools) > 0 {
+		return EvaluateOptions{}, fmt.Errorf("the pools are only used by a lineup template, set one with --lineup")
+	}
+
+	return EvaluateOptions{
+		NumGames:      c.Int("games"),
+		Against:       c.StringSlice("against"),
+		Limits:        gameLimits(c),
+		MaxFailures:   c.Int("max-failures"),
+		SelectionSeed: selectionSeed,
+		KeepLogs:      c.Bool("keep-logs"),
+		Cnf:           &Cnf{Overrides: c.StringSlice("cnf-set")},
+		RotateSeats:   c.Bool("rotate"),
+		Reuse:         c.Bool("reuse"),
+		Rating:        c.String("rating"),
+		SPRT:          sprt,
+		Scheduler:     c.String("scheduler"),
+		Pools:         pools,
+		Lineup:        lineup,
+		Duration:      c.Duration("duration"),
+	}, nil
+}
+
+// evaluationSession returns the session to play, either a new one from the
+// flags or the one to resume with --resume
+func evaluationSession(c *cli.Context) (*Session, error) {
+	if c.Bool("resume") {
+		session, err := loadResumableSession(c.String("session"))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := session.Resume(); err != nil {
+			return nil, err
+		}
+
+		fmt.Printf("Resuming session %s of %s after %s\n", text.Bold.Sprint(session.ID), session.Ai, sessionProgress(session))
+
+		// The test starts over from the recorded games
+		if session.Options.SPRT != nil {
+			session.Options.SPRT.LLRs = nil
+			session.Options.SPRT.Decision = SPRTContinue
+		}
+
+		return session, nil
+	} else if len(c.String("session")) > 0 {
+		return nil, fmt.Errorf("--session can only be used together with --resume")
+	}
+
 	myAi, err := ai.GetAi(ai.DescriptorFromString(c.String("ai")))
+	if err != nil {
+		return nil, err
+	}
 
+	options, err := evaluateOptions(c)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return NewSession(myAi, options), nil
+}
+
+// sessionProgress describes how much of a session has been played
+func sessionProgress(session *Session) string {
+	if session.Options.Duration > 0 {
+		return fmt.Sprintf("%d games in %s of %s", len(session.Games), session.Elapsed.Round(time.Second), session.Options.Duration)
 	}
 
+	return fmt.Sprintf("%d of %d games", len(session.Games), session.Options.TotalGames())
+}
+
+// newProgressWriter returns the writer that shows the progress of the commands
+// that play many games, which stops once all its trackers are done
+func newProgressWriter() progress.Writer {
 	pw := progress.NewWriter()
 	pw.SetTrackerLength(20)
-	//pw.ShowOverallTracker(true)
 	pw.ShowOverallTracker(true)
 	pw.ShowTime(false)
 	pw.ShowTracker(false)
@@ -185,11 +572,95 @@ func evaluate(c *cli.Context) error {
 	pw.Style().Colors = progress.StyleColorsExample
 	pw.Style().Chars = progress.StyleCharsCircle
 
+	return pw
+}
+
+// budgetTracker returns a tracker of the time spent out of a time budget,
+// which shows the time left and updates itself every second
+func budgetTracker(budget time.Duration, elapsed time.Duration) *progress.Tracker {
+	total := int64(budget / time.Second)
+
+	tracker := &progress.Tracker{
+		Message: fmt.Sprintf("Time budget %s", budget),
+		Total:   total,
+		Units: progress.Units{Formatter: func(value int64) string {
+			return fmt.Sprintf("%s left", time.Duration(total-value)*time.Second)
+		}},
+	}
+
+	start := time.Now()
+
+	go func() {
+		for !tracker.IsDone() {
+			value := int64((elapsed + time.Since(start)) / time.Second)
+			if value > total {
+				value = total
+			}
+
+			tracker.SetValue(value)
+			time.Sleep(time.Second)
+		}
+	}()
+
+	return tracker
+}
+
+// interruptOnSignal returns a channel that is closed on the first SIGINT or
+// SIGTERM, while the second one kills the running games. Games run in their
+// own process group, so they do not get the signals of the terminal
+func interruptOnSignal() <-chan struct{} {
+	interrupt := make(chan struct{})
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		<-signals
+		fmt.Println("\nInterrupted, waiting for the running games, press Ctrl-C again to kill them")
+		close(interrupt)
+
+		<-signals
+		fmt.Println("\nKilling the running games")
+		utils.KillRunning()
+	}()
+
+	return interrupt
+}
+
+func evaluate(c *cli.Context) error {
+	if reportFile := c.String("report"); len(reportFile) > 0 {
+		if err := checkReportFile(reportFile); err != nil {
+			return err
+		}
+	}
+
+	session, err := evaluationSession(c)
+
+	if err != nil {
+		return err
+	}
+
+	myAi, err := ai.GetAi(ai.DescriptorFromString(session.Ai))
+
+	if err != nil {
+		return err
+	}
+
+	cnf, err := sessionCnf(session.Options.Cnf.Overrides)
+
+	if err != nil {
+		return err
+	}
+	defer cnf.Remove()
+
+	options := session.Options
+	options.Cnf = cnf
+
+	pw := newProgressWriter()
+
 	go pw.Render()
 
 	fmt.Printf("Compiling ... ")
 
-	//pw.AppendTracker(&trackerCompile)
 	err = utils.Compile()
 	fmt.Printf("done\n")
 
@@ -197,28 +668,71 @@ func evaluate(c *cli.Context) error {
 		return err
 	}
 
-	numGames := c.Int("games")
-	trackerMessage := fmt.Sprintf("Running %d games", numGames)
-	trackerEvaluate := progress.Tracker{Message: trackerMessage, Total: int64(numGames)}
-	pw.AppendTracker(&trackerEvaluate)
+	if err := session.Save(); err != nil {
+		return err
+	}
 
-	evaluations, err := Evaluate(myAi, numGames, c.StringSlice("against"), func() {
-		trackerEvaluate.Increment(1)
-	})
+	options.Interrupt = interruptOnSignal()
+
+	var trackerEvaluate *progress.Tracker
+	onGameFinished := func() {}
+
+	if options.Duration > 0 {
+		trackerEvaluate = budgetTracker(options.Duration, session.Elapsed)
+	} else {
+		numGames := options.TotalGames()
+		trackerMessage := fmt.Sprintf("Running %d games", numGames)
+		trackerEvaluate = &progress.Tracker{Message: trackerMessage, Total: int64(numGames)}
+		onGameFinished = func() {
+			trackerEvaluate.Increment(1)
+		}
+	}
+	pw.AppendTracker(trackerEvaluate)
+
+	result, err := Evaluate(myAi, options, session, onGameFinished)
 	trackerEvaluate.MarkAsDone()
 	pw.Stop()
 
-	if err != nil {
+	switch {
+	case result != nil && result.Interrupted:
+		session.Status = SessionInterrupted
+	case err != nil:
+		session.Status = SessionStopped
+	default:
+		session.Status = SessionFinished
+	}
+
+	if errSave := session.Save(); errSave != nil && err == nil {
+		err = errSave
+	}
+
+	if result == nil {
 		return err
 	}
 
+	fmt.Printf("Selection seed %s, use --selection-seed %d to reproduce this evaluation\n",
+		text.Bold.Sprint(result.SelectionSeed), result.SelectionSeed)
+
+	if options.Duration > 0 {
+		fmt.Printf("Completed %s games in %s of the %s time budget\n",
+			text.Bold.Sprint(len(session.Games)), session.Elapsed.Round(time.Second), options.Duration)
+	}
+
+	if options.Reuse {
+		fmt.Printf("Reused %s games from previous sessions", text.Bold.Sprint(result.NumReused))
+		if result.NumOutdated > 0 {
+			fmt.Printf(", %d outdated games were left out because an AI, the game or its configuration changed", result.NumOutdated)
+		}
+		fmt.Println()
+	}
+
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
 	t.SetStyle(table.StyleColoredGreenWhiteOnBlack)
 	t.SetTitle("Ranking")
-	t.AppendHeader(table.Row{"#", "AI", "Elo", "1st%", "<=2nd%", "<=3rd%", "EvWin%", "Score", "95%", "99%", "Games"})
+	t.AppendHeader(table.Row{"#", "AI", "Rating", "1st%", "<=2nd%", "<=3rd%", "EvWin%", "Score", "95%", "99%", "CPU%", "MaxCPU%", "Games"})
 
-	for i, evaluation := range evaluations {
+	for i, evaluation := range result.Ranking {
 		numGames := len(evaluation.Scores)
 
 		data := make([]float64, numGames)
@@ -236,6 +750,15 @@ func evaluate(c *cli.Context) error {
 		percentile99, _ := stats.Percentile(data, 99)
 		winPercentageEvaluated := 100 * float64(evaluation.NumWinsEvaluated) / float64(numGames)
 
+		cpu, maxCPU := "-", "-"
+		if len(evaluation.CPUs) > 0 {
+			avgCPU, _ := stats.Mean(evaluation.CPUs)
+			maxCPUValue, _ := stats.Max(evaluation.CPUs)
+			cpu, maxCPU = ff(100*avgCPU), ff(100*maxCPUValue)
+		}
+
+		rating := ratingString(evaluation.Rating, evaluation.RatingDeviation, evaluation.RatingInterval)
+
 		playerSuffix := ""
 
 		isSpecial := evaluation.Player == myAi.PlayerName() || i == 0
@@ -248,7 +771,7 @@ func evaluate(c *cli.Context) error {
 		t.AppendRow(table.Row{
 			strconv.Itoa(i+1) + playerSuffix,
 			fr(evaluation.Player, isSpecial),
-			fr(evaluation.Elo, isSpecial),
+			fr(rating, isSpecial),
 			fr(fw(firstPercentage), isSpecial),
 			fr(fw(secondPercentage), isSpecial),
 			fr(fw(thirdPercentage), isSpecial),
@@ -256,43 +779,905 @@ func evaluate(c *cli.Context) error {
 			fr(fmt.Sprintf(`%.2f ± %.2f%s`, avgScore, 100*stdevScore/avgScore, "%"), isSpecial),
 			fr(ff(percentile95), isSpecial),
 			fr(ff(percentile99), isSpecial),
+			fr(cpu, isSpecial),
+			fr(maxCPU, isSpecial),
 			fr(numGames, isSpecial),
 		})
 	}
 
 	t.Render()
 
-	return nil
-}
+	for _, evaluation := range result.Ranking {
+		maxCPU, _ := stats.Max(evaluation.CPUs)
+		warnCPU(evaluation.Player, maxCPU, evaluation.NumDisqualified, c.Float64("cpu-warning"))
+	}
 
-func before(c *cli.Context) error {
-	return altsrc.InitInputSourceWithContext(c.Command.Flags, altsrc.NewTomlSourceFromFlagFunc("config"))(c)
-}
+	if c.Bool("matrix") {
+		renderMatrix(result.Ranking)
+	}
 
-func main() {
-	if _, err := os.Stat("dojo.toml"); os.IsNotExist(err) {
-		fmt.Println("Config file dojo.toml not found in current directory. See https://github.com/albertsgrc/dojo/blob/master/dojo.toml for an example")
-		os.Exit(1)
+	if matrixCSV := c.String("matrix-csv"); len(matrixCSV) > 0 {
+		if err := writeMatrixCSV(matrixCSV, result.Ranking); err != nil {
+			return err
+		}
+
+		fmt.Printf("Head to head matrix written to %s\n", matrixCSV)
 	}
 
-	generalFlags := []cli.Flag{
-		altsrc.NewStringFlag(&cli.StringFlag{
-			Name:        "current-ai",
-			Aliases:     []string{"ai"},
-			Usage:       "the AI descriptor of the AI you are currently working with",
-			DefaultText: "Demo",
-			Value:       "Demo",
-		}),
-		&cli.StringFlag{
-			Name:        "config",
-			Usage:       "path to the configuration file",
-			DefaultText: "dojo.toml file in current directory",
-			Value:       "dojo.toml",
-		},
+	if options.RotateSeats {
+		renderSeats(myAi, result.Ranking)
 	}
 
-	commands := []*cli.Command{
-		{
+	if result.SPRT != nil {
+		renderSPRT(result.SPRT, fmt.Sprintf("%s against the rest", myAi.PlayerName()))
+	}
+
+	if c.Bool("timeline") {
+		renderTimelines(myAi, result.Ranking)
+	}
+
+	if len(result.Failures) > 0 {
+		renderFailures(result.Failures)
+	}
+
+	if reportFile := c.String("report"); len(reportFile) > 0 {
+		// The ranking is the one just shown, only dojo report rates the games again
+		games, errReport := sessionGames(session)
+		if errReport == nil {
+			evaluationReport := &Report{Session: session, Evaluation: result, Games: games, Generated: time.Now()}
+			errReport = evaluationReport.Write(reportFile)
+		}
+
+		if errReport != nil {
+			return errReport
+		}
+
+		fmt.Printf("Report written to %s\n", reportFile)
+	}
+
+	if result.Interrupted {
+		fmt.Printf("⏸️  Interrupted after %s, continue with %s\n",
+			sessionProgress(session), text.Bold.Sprintf("dojo evaluate --resume --session %s", session.ID))
+	}
+
+	return err
+}
+
+// renderMatrix shows the percentage of the games shared by the AI of each row
+// and the AI of each column where the first one finished ahead, and the
+// number of games they shared
+func renderMatrix(ranking []*EvaluationResult) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	t.SetTitle("Head to head")
+
+	header := table.Row{""}
+	for _, opponent := range ranking {
+		header = append(header, opponent.Player)
+	}
+	t.AppendHeader(header)
+
+	for _, evaluation := range ranking {
+		row := table.Row{evaluation.Player}
+
+		for _, opponent := range ranking {
+			if headToHead, ok := evaluation.HeadToHead[opponent.Player]; ok {
+				row = append(row, fmt.Sprintf("%s (%d)", fw(headToHead.WinPercentage()), headToHead.NumGames))
+			} else {
+				row = append(row, "-")
+			}
+		}
+
+		t.AppendRow(row)
+	}
+
+	t.Render()
+}
+
+// writeMatrixCSV writes the head to head matrix to a CSV file, with the win
+// percentage and the number of games against each opponent in two columns
+func writeMatrixCSV(fileName string, ranking []*EvaluationResult) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	header := []string{"AI"}
+	for _, opponent := range ranking {
+		header = append(header, opponent.Player+" win%", opponent.Player+" games")
+	}
+	writer.Write(header)
+
+	for _, evaluation := range ranking {
+		row := []string{evaluation.Player}
+
+		for _, opponent := range ranking {
+			if headToHead, ok := evaluation.HeadToHead[opponent.Player]; ok {
+				row = append(row, strconv.FormatFloat(headToHead.WinPercentage(), 'f', 2, 64), strconv.Itoa(headToHead.NumGames))
+			} else {
+				row = append(row, "", "0")
+			}
+		}
+
+		writer.Write(row)
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// renderSeats shows the results of the evaluated AI in each seat, next to the
+// mean score of all the AIs in that seat, which shows the bias of each position
+func renderSeats(myAi *ai.Ai, ranking []*EvaluationResult) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	t.SetTitle("Seats")
+	t.AppendHeader(table.Row{"Seat", "Score", "1st%", "Games", "All AIs score"})
+
+	for seat := 0; seat < 4; seat++ {
+		all := make([]float64, 0)
+		mine := make([]float64, 0)
+		numWins := 0
+
+		for _, evaluation := range ranking {
+			if seat >= len(evaluation.Seats) {
+				continue
+			}
+
+			for _, score := range evaluation.Seats[seat].Scores {
+				all = append(all, float64(score))
+
+				if evaluation.Player == myAi.PlayerName() {
+					mine = append(mine, float64(score))
+				}
+			}
+
+			if evaluation.Player == myAi.PlayerName() {
+				numWins = evaluation.Seats[seat].NumWins
+			}
+		}
+
+		avgAll, _ := stats.Mean(all)
+		row := table.Row{seat, "-", "-", len(mine), ff(avgAll)}
+
+		if len(mine) > 0 {
+			avgScore, _ := stats.Mean(mine)
+			stdevScore, _ := stats.StandardDeviation(mine)
+			row[1] = fmt.Sprintf(`%.2f ± %.2f`, avgScore, stdevScore)
+			row[2] = fw(100 * float64(numWins) / float64(len(mine)))
+		}
+
+		t.AppendRow(row)
+	}
+
+	t.Render()
+}
+
+// renderSPRT prints the decision of a sequential test and the trajectory of
+// its log-likelihood ratio
+func renderSPRT(sprt *SPRT, hypothesis string) {
+	lower, upper := sprt.Bounds()
+	llr := sprt.LLR()
+
+	fmt.Println()
+	fmt.Printf("%s SPRT %s of %s\n", text.Bold.Sprint("Sequential test"), sprt, hypothesis)
+
+	switch sprt.Decision {
+	case SPRTAcceptH1:
+		fmt.Printf("✅ H1 accepted after %d games, the Elo difference is at least %g (LLR %.2f >= %.2f)\n", len(sprt.LLRs), sprt.Elo1, llr, upper)
+	case SPRTAcceptH0:
+		fmt.Printf("❌ H0 accepted after %d games, the Elo difference is at most %g (LLR %.2f <= %.2f)\n", len(sprt.LLRs), sprt.Elo0, llr, lower)
+	default:
+		fmt.Printf("🤷 No decision after %d games (LLR %.2f, bounds %.2f and %.2f), play more games\n", len(sprt.LLRs), llr, lower, upper)
+	}
+
+	if len(sprt.LLRs) == 0 {
+		return
+	}
+
+	lowerBound, upperBound := make([]float64, len(sprt.LLRs)), make([]float64, len(sprt.LLRs))
+	for i := range sprt.LLRs {
+		lowerBound[i], upperBound[i] = lower, upper
+	}
+
+	fmt.Print(utils.Chart([]utils.Series{
+		{Name: "LLR", Values: sprt.LLRs},
+		{Name: "accept H1", Values: upperBound},
+		{Name: "accept H0", Values: lowerBound},
+	}, 72, 12))
+}
+
+// maxTimelines is the maximum number of AIs whose timeline is plotted together
+const maxTimelines = 6
+
+func renderTimelines(myAi *ai.Ai, ranking []*EvaluationResult) {
+	series := make([]utils.Series, 0)
+
+	for _, evaluation := range ranking {
+		if evaluation.Player == myAi.PlayerName() {
+			series = append(series, utils.Series{Name: evaluation.Player + "✨", Values: evaluation.Timeline})
+		}
+	}
+
+	for _, evaluation := range ranking {
+		if len(series) < maxTimelines && evaluation.Player != myAi.PlayerName() {
+			series = append(series, utils.Series{Name: evaluation.Player, Values: evaluation.Timeline})
+		}
+	}
+
+	fmt.Println()
+	fmt.Println(text.Bold.Sprint("Mean score by round"))
+	fmt.Print(utils.Chart(series, 72, 16))
+}
+
+func reproduceCommand(gameResult GameResult) string {
+	if len(gameResult.ID) > 0 {
+		return "dojo rerun " + gameResult.ID
+	}
+
+	command := "dojo run --seed " + gameResult.Seed
+
+	for _, player := range gameResult.Players {
+		command += " -p " + ai.PlayerDescriptor(player)
+	}
+
+	return command
+}
+
+func renderFailures(failures []GameResult) {
+	fmt.Println()
+	utils.Error(fmt.Sprintf("%d games failed and were not taken into account", len(failures)))
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleColoredRedWhiteOnBlack)
+	t.SetTitle("Failures")
+	t.AppendHeader(table.Row{"#", "Game", "Outcome", "Player", "Seed", "Lineup", "Reproduce with"})
+
+	for i, gameResult := range failures {
+		t.AppendRow(table.Row{
+			i + 1,
+			gameResult.ID,
+			gameResult.Outcome,
+			gameResult.FailedPlayer,
+			gameResult.Seed,
+			strings.Join(gameResult.Players, " "),
+			reproduceCommand(gameResult),
+		})
+	}
+
+	t.Render()
+}
+
+func match(c *cli.Context) error {
+	seeds, err := parseSeeds(c.String("seeds"))
+
+	if err != nil {
+		return err
+	}
+
+	selectionSeed, err := parseSeed(c.String("selection-seed"))
+
+	if err != nil {
+		return err
+	}
+
+	pw := newProgressWriter()
+
+	go pw.Render()
+
+	fmt.Printf("Compiling ... ")
+	err = utils.Compile()
+	fmt.Printf("done\n")
+
+	if err != nil {
+		return err
+	}
+
+	trackerMatch := progress.Tracker{Message: fmt.Sprintf("Running %d games", len(seeds)), Total: int64(len(seeds))}
+	pw.AppendTracker(&trackerMatch)
+
+	result, err := Match(c.StringSlice("players"), MatchOptions{
+		Seeds:         seeds,
+		RotateSeats:   c.Bool("rotate"),
+		Limits:        gameLimits(c),
+		MaxFailures:   c.Int("max-failures"),
+		SelectionSeed: selectionSeed,
+		Origin:        "match",
+	}, func() {
+		trackerMatch.Increment(1)
+	})
+	trackerMatch.MarkAsDone()
+	pw.Stop()
+
+	if result == nil {
+		return err
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	t.SetTitle("Match")
+	t.AppendHeader(table.Row{"AI", "Score", "1st%", "2nd%", "3rd%", "4th%", "Games"})
+
+	for _, player := range result.Players {
+		numGames := len(player.Scores)
+		data := make([]float64, numGames)
+
+		for i, score := range player.Scores {
+			data[i] = float64(score)
+		}
+
+		// All the games may have failed
+		if numGames == 0 {
+			t.AppendRow(table.Row{player.Label, "-", "-", "-", "-", "-", numGames})
+			continue
+		}
+
+		avgScore, _ := stats.Mean(data)
+		stdevScore, _ := stats.StandardDeviation(data)
+
+		row := table.Row{player.Label, fmt.Sprintf(`%.2f ± %.2f`, avgScore, stdevScore)}
+		for _, numGamesAtPlace := range player.NumGamesAtPlace {
+			row = append(row, fw(100*float64(numGamesAtPlace)/float64(numGames)))
+		}
+		row = append(row, numGames)
+
+		t.AppendRow(row)
+	}
+
+	t.Render()
+
+	h := table.NewWriter()
+	h.SetOutputMirror(os.Stdout)
+	h.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	h.SetTitle("Head to head")
+
+	header := table.Row{""}
+	for _, player := range result.Players {
+		header = append(header, player.Label)
+	}
+	h.AppendHeader(header)
+
+	for i, player := range result.Players {
+		row := table.Row{player.Label}
+
+		for j, numGamesAhead := range player.NumGamesAhead {
+			if i == j || len(player.Scores) == 0 {
+				row = append(row, "-")
+			} else {
+				row = append(row, fw(100*float64(numGamesAhead)/float64(len(player.Scores))))
+			}
+		}
+
+		h.AppendRow(row)
+	}
+
+	h.Render()
+
+	if len(result.Failures) > 0 {
+		renderFailures(result.Failures)
+	}
+
+	return err
+}
+
+// smoke plays a few short games of an AI against Dummy, which is enough to
+// detect most crashes
+func smoke(c *cli.Context) error {
+	descriptor := c.Args().First()
+	if len(descriptor) == 0 {
+		descriptor = c.String("ai")
+	}
+
+	numGames := c.Int("games")
+	if numGames <= 0 {
+		return fmt.Errorf("Invalid number of games '%d'", numGames)
+	}
+
+	cnf, err := sessionCnf(c.StringSlice("cnf-set"))
+
+	if err != nil {
+		return err
+	}
+	defer cnf.Remove()
+
+	fmt.Printf("Compiling ... ")
+	err = utils.Compile()
+	fmt.Printf("done\n")
+
+	if err != nil {
+		return err
+	}
+
+	seeds := make([]int64, numGames)
+	for i := range seeds {
+		seeds[i] = int64(i + 1)
+	}
+
+	numPlayed := 0
+
+	result, err := Match([]string{descriptor, "Dummy", "Dummy", "Dummy"}, MatchOptions{
+		Seeds:         seeds,
+		RotateSeats:   true,
+		Limits:        gameLimits(c),
+		MaxFailures:   -1,
+		SelectionSeed: timeSeed(),
+		Cnf:           cnf,
+		Origin:        "smoke",
+	}, func() {
+		numPlayed++
+		fmt.Printf("\rPlayed %d of %d games", numPlayed, numGames)
+	})
+	fmt.Println()
+
+	if result == nil {
+		return err
+	}
+
+	if len(result.Failures) > 0 {
+		renderFailures(result.Failures)
+		return fmt.Errorf("%d of %d smoke games failed", len(result.Failures), numGames)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ %s played %d games against Dummy without failures\n", result.Players[0].Player, numGames)
+
+	return nil
+}
+
+func compare(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("expected the 2 AIs to compare, with the options before them, e.g. dojo compare --games 200 Dojo:5 Dojo:4")
+	}
+
+	selectionSeed, err := parseSeed(c.String("selection-seed"))
+
+	if err != nil {
+		return err
+	}
+
+	cnf, err := sessionCnf(c.StringSlice("cnf-set"))
+
+	if err != nil {
+		return err
+	}
+	defer cnf.Remove()
+
+	pw := newProgressWriter()
+
+	go pw.Render()
+
+	fmt.Printf("Compiling ... ")
+	err = utils.Compile()
+	fmt.Printf("done\n")
+
+	if err != nil {
+		return err
+	}
+
+	var sprt *SPRT
+	if len(c.String("sprt")) > 0 {
+		if sprt, err = parseSPRT(c.String("sprt")); err != nil {
+			return err
+		}
+	}
+
+	numGames := c.Int("games")
+	trackerCompare := progress.Tracker{Message: fmt.Sprintf("Running %d games", 2*numGames), Total: int64(2 * numGames)}
+	pw.AppendTracker(&trackerCompare)
+
+	comparison, err := Compare([2]string{c.Args().Get(0), c.Args().Get(1)}, CompareOptions{
+		NumGames:      numGames,
+		Against:       c.StringSlice("against"),
+		Limits:        gameLimits(c),
+		MaxFailures:   c.Int("max-failures"),
+		SelectionSeed: selectionSeed,
+		Cnf:           cnf,
+		SPRT:          sprt,
+	}, func() {
+		trackerCompare.Increment(1)
+	})
+	trackerCompare.MarkAsDone()
+	pw.Stop()
+
+	if comparison == nil {
+		return err
+	}
+
+	fmt.Printf("Selection seed %s, use --selection-seed %d to reproduce this comparison\n",
+		text.Bold.Sprint(comparison.SelectionSeed), comparison.SelectionSeed)
+
+	numScenarios := len(comparison.Results)
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	t.SetTitle("Comparison")
+	t.AppendHeader(table.Row{"AI", "Score", "1st%", "Games"})
+
+	for i, candidate := range comparison.Candidates {
+		data := make([]float64, numScenarios)
+		numWins := 0
+
+		for j, result := range comparison.Results {
+			data[j] = float64(result.Scores[i])
+
+			if result.Wins[i] {
+				numWins++
+			}
+		}
+
+		avgScore, _ := stats.Mean(data)
+		stdevScore, _ := stats.StandardDeviation(data)
+
+		t.AppendRow(table.Row{
+			candidate,
+			fmt.Sprintf(`%.2f ± %.2f`, avgScore, stdevScore),
+			fw(100 * float64(numWins) / float64(numScenarios)),
+			numScenarios,
+		})
+	}
+
+	t.Render()
+
+	if len(comparison.Failures) > 0 {
+		renderFailures(comparison.Failures)
+	}
+
+	if numScenarios < 2 {
+		return fmt.Errorf("not enough scenarios finished correctly to compare the AIs")
+	}
+
+	scoreTest := utils.OneSampleTTest(comparison.ScoreDifferences())
+	winTest := utils.OneSampleTTest(comparison.WinDifferences())
+	scoreLow, scoreHigh := scoreTest.Interval(0.95)
+	winLow, winHigh := winTest.Interval(0.95)
+
+	fmt.Printf("\n%s - %s over %d paired scenarios\n", comparison.Candidates[0], comparison.Candidates[1], numScenarios)
+	fmt.Printf("   Score    %+.2f (95%% CI %+.2f .. %+.2f), p = %.4f\n", scoreTest.Mean, scoreLow, scoreHigh, scoreTest.P)
+	fmt.Printf("   1st%%     %+.2f (95%% CI %+.2f .. %+.2f), p = %.4f\n", 100*winTest.Mean, 100*winLow, 100*winHigh, winTest.P)
+	fmt.Println()
+
+	if comparison.SPRT != nil {
+		renderSPRT(comparison.SPRT, fmt.Sprintf("%s against %s", comparison.Candidates[0], comparison.Candidates[1]))
+		fmt.Println()
+	}
+
+	if scoreTest.P < 0.05 {
+		better, worse := comparison.Candidates[0], comparison.Candidates[1]
+		if scoreTest.Mean < 0 {
+			better, worse = worse, better
+		}
+
+		fmt.Printf("✅ %s scores significantly more than %s (paired t-test, p < 0.05)\n", text.Bold.Sprint(better), worse)
+	} else {
+		fmt.Println("🤷 No significant score difference (paired t-test, p >= 0.05), play more games to tell them apart")
+	}
+
+	return err
+}
+
+// listRatings rates the AIs with all the recorded games that are up to date
+func listRatings(c *cli.Context) error {
+	descriptors := make([]ai.Descriptor, 0)
+	for _, descriptor := range c.Args().Slice() {
+		descriptors = append(descriptors, ai.DescriptorFromString(descriptor))
+	}
+
+	if len(descriptors) == 0 {
+		descriptors = append(descriptors, ai.DescriptorFromString(":"))
+	}
+
+	ratings, err := newRatingSystem(c.String("rating"))
+
+	if err != nil {
+		return err
+	}
+
+	gameResults, numOutdated, err := reusableGames(ai.List(descriptors...), defaultCnf, "")
+
+	if err != nil {
+		return err
+	}
+
+	if len(gameResults) == 0 {
+		return fmt.Errorf("no recorded games found between the given AIs")
+	}
+
+	numGames := make(map[string]int)
+
+	for _, gameResult := range gameResults {
+		players, scores := ratedScores(gameScores(gameResult))
+		ratings.Update(players, scores)
+
+		for _, player := range players {
+			numGames[player]++
+		}
+	}
+
+	results := make([]*EvaluationResult, 0, len(numGames))
+	for player := range numGames {
+		result := &EvaluationResult{Player: player}
+		result.Rating, result.RatingDeviation = ratings.Rating(player)
+
+		if intervals, ok := ratings.(intervalRatingSystem); ok {
+			low, high := intervals.Interval(player)
+			result.RatingInterval = []float64{low, high}
+		}
+
+		results = append(results, result)
+	}
+
+	sort.Sort(ByRatingDescending(results))
+
+	fmt.Printf("Rated with %s recorded games", text.Bold.Sprint(len(gameResults)))
+	if numOutdated > 0 {
+		fmt.Printf(", %d outdated games were left out because an AI, the game or its configuration changed", numOutdated)
+	}
+	fmt.Println()
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	t.SetTitle("Ratings")
+	t.AppendHeader(table.Row{"#", "AI", "Rating", "Games"})
+
+	for i, result := range results {
+		t.AppendRow(table.Row{
+			i + 1,
+			result.Player,
+			ratingString(result.Rating, result.RatingDeviation, result.RatingInterval),
+			numGames[result.Player],
+		})
+	}
+
+	t.Render()
+
+	return nil
+}
+
+// report writes the report of a recorded evaluation session, or lists the
+// sessions when no id is given
+func report(c *cli.Context) error {
+	if c.NArg() == 0 {
+		ids, err := sessionIDs()
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			fmt.Println("No evaluation sessions have been recorded yet")
+			return nil
+		}
+
+		for _, id := range ids {
+			session, err := loadSession(id)
+			if err != nil {
+				continue
+			}
+
+			fmt.Printf("%s  %-12s %-11s %s\n", text.Bold.Sprint(id), session.Player, session.Status, sessionProgress(session))
+		}
+
+		return nil
+	}
+
+	if c.NArg() > 1 {
+		return fmt.Errorf("report expects a single session id, found %d arguments", c.NArg())
+	}
+
+	evaluationReport, err := loadReport(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	output := c.String("output")
+	if len(output) == 0 {
+		output = evaluationReport.Session.ID + ".html"
+	}
+
+	if err := evaluationReport.Write(output); err != nil {
+		return err
+	}
+
+	fmt.Printf("Report of session %s written to %s\n", evaluationReport.Session.ID, text.Bold.Sprint(output))
+
+	return nil
+}
+
+func checkDeterminism(c *cli.Context) error {
+	descriptor := c.Args().First()
+	if len(descriptor) == 0 {
+		descriptor = c.String("ai")
+	}
+
+	opponents := c.StringSlice("opponents")
+	if len(opponents) != 3 {
+		return fmt.Errorf("Invalid number of opponents '%d', expected 3", len(opponents))
+	}
+
+	numSeeds := c.Int("seeds")
+
+	pw := newProgressWriter()
+
+	go pw.Render()
+
+	fmt.Printf("Compiling ... ")
+	err := utils.Compile()
+	fmt.Printf("done\n")
+
+	if err != nil {
+		return err
+	}
+
+	trackerCheck := progress.Tracker{Message: fmt.Sprintf("Checking %d seeds", numSeeds), Total: int64(numSeeds)}
+	pw.AppendTracker(&trackerCheck)
+
+	checks, err := CheckDeterminism(append([]string{descriptor}, opponents...), numSeeds, gameLimits(c), func() {
+		trackerCheck.Increment(1)
+	})
+	trackerCheck.MarkAsDone()
+	pw.Stop()
+
+	if err != nil {
+		return err
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetStyle(table.StyleColoredGreenWhiteOnBlack)
+	t.SetTitle("Non-deterministic seeds")
+	t.AppendHeader(table.Row{"Seed", "Players", "Diverges at", "Scores", "Game IDs"})
+
+	numDiverging, numErrors := 0, 0
+
+	for _, check := range checks {
+		if check.Deterministic() {
+			continue
+		}
+
+		divergence := check.Divergence
+		if len(check.Error) > 0 {
+			divergence = check.Error
+			numErrors++
+		} else {
+			numDiverging++
+		}
+
+		t.AppendRow(table.Row{
+			check.Seed,
+			strings.Join(check.Players, " "),
+			divergence,
+			fmt.Sprint(check.Games[0].Scores) + "\n" + fmt.Sprint(check.Games[1].Scores),
+			check.Games[0].ID + "\n" + check.Games[1].ID,
+		})
+	}
+
+	if numDiverging+numErrors > 0 {
+		t.Render()
+	}
+
+	if numErrors > 0 {
+		utils.Warning(fmt.Sprintf("%d of %d seeds could not be checked because a game failed", numErrors, numSeeds))
+	}
+
+	if numDiverging > 0 {
+		return fmt.Errorf("%s is not deterministic, %d of %d seeds diverged", descriptor, numDiverging, numSeeds)
+	}
+
+	fmt.Printf("%s is deterministic on %d of %d seeds\n", descriptor, numSeeds-numErrors, numSeeds)
+
+	return nil
+}
+
+func before(c *cli.Context) error {
+	return altsrc.InitInputSourceWithContext(c.Command.Flags, altsrc.NewTomlSourceFromFlagFunc("config"))(c)
+}
+
+func main() {
+	if _, err := os.Stat("dojo.toml"); os.IsNotExist(err) {
+		fmt.Println("Config file dojo.toml not found in current directory. See https://github.com/albertsgrc/dojo/blob/master/dojo.toml for an example")
+		os.Exit(1)
+	}
+
+	generalFlags := []cli.Flag{
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "current-ai",
+			Aliases:     []string{"ai"},
+			Usage:       "the AI descriptor of the AI you are currently working with",
+			DefaultText: "Demo",
+			Value:       "Demo",
+		}),
+		altsrc.NewDurationFlag(&cli.DurationFlag{
+			Name:        "game.timeout",
+			Aliases:     []string{"timeout"},
+			Usage:       "wall-clock time limit for a single game, 0 means no limit",
+			DefaultText: "2m",
+			Value:       2 * time.Minute,
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:        "game.memory-limit",
+			Aliases:     []string{"memory-limit"},
+			Usage:       "virtual memory limit for a single game in `MB`, 0 means no limit",
+			DefaultText: "0",
+			Value:       0,
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:        "game.cpu-limit",
+			Aliases:     []string{"cpu-limit"},
+			Usage:       "CPU time limit for a single game in `SECONDS`, 0 means no limit",
+			DefaultText: "0",
+			Value:       0,
+		}),
+		altsrc.NewFloat64Flag(&cli.Float64Flag{
+			Name:        "game.cpu-warning",
+			Aliases:     []string{"cpu-warning"},
+			Usage:       "warn when a player uses more than `PERCENT` of its time budget",
+			DefaultText: "80",
+			Value:       80,
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "debug.make-target",
+			Aliases:     []string{"debug-target"},
+			Usage:       "make target that builds the debug variant of the game, with sanitizers",
+			DefaultText: "debug",
+			Value:       "debug",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "debug.game",
+			Aliases:     []string{"debug-game"},
+			Usage:       "game executable built by the debug make target",
+			DefaultText: "Game.debug",
+			Value:       "Game.debug",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "view.viewer",
+			Aliases:     []string{"viewer"},
+			Usage:       "folder that contains the game's HTML viewer",
+			DefaultText: "Viewer",
+			Value:       "Viewer",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "view.viewer-file",
+			Aliases:     []string{"viewer-file"},
+			Usage:       "HTML file of the viewer, relative to the viewer folder",
+			DefaultText: "viewer.html",
+			Value:       "viewer.html",
+		}),
+		altsrc.NewStringFlag(&cli.StringFlag{
+			Name:        "view.viewer-param",
+			Aliases:     []string{"viewer-param"},
+			Usage:       "query parameter of the viewer URL that is set to the replay",
+			DefaultText: "game",
+			Value:       "game",
+		}),
+		altsrc.NewIntFlag(&cli.IntFlag{
+			Name:        "view.port",
+			Aliases:     []string{"port"},
+			Usage:       "port of the local viewer server, 0 picks a free port",
+			DefaultText: "8000",
+			Value:       8000,
+		}),
+		&cli.StringFlag{
+			Name:        "config",
+			Usage:       "path to the configuration file",
+			DefaultText: "dojo.toml file in current directory",
+			Value:       "dojo.toml",
+		},
+	}
+
+	commands := []*cli.Command{
+		{
 			Name:  "ai",
 			Usage: "AI related operations",
 			Subcommands: []*cli.Command{
@@ -341,6 +1726,40 @@ func main() {
 					DefaultText: "0",
 					Value:       "0",
 				}),
+				altsrc.NewStringFlag(&cli.StringFlag{
+					Name:        "run.selection-seed",
+					Aliases:     []string{"selection-seed"},
+					Usage:       "set the seed used to pick the player versions and seats, either a number or the string 'time'",
+					DefaultText: "time",
+					Value:       "time",
+				}),
+				altsrc.NewBoolFlag(&cli.BoolFlag{
+					Name:        "run.timeline",
+					Aliases:     []string{"timeline"},
+					Usage:       "plot the score of each player over the rounds",
+					DefaultText: "false",
+					Value:       false,
+				}),
+				altsrc.NewBoolFlag(&cli.BoolFlag{
+					Name:        "run.view",
+					Aliases:     []string{"view"},
+					Usage:       "open the replay in the game's viewer after the game finishes",
+					DefaultText: "false",
+					Value:       false,
+				}),
+				&cli.BoolFlag{
+					Name:  "no-open",
+					Usage: "with --view, do not open the viewer in the browser, only print its URL",
+				},
+				&cli.BoolFlag{
+					Name:  "debug",
+					Usage: "run the debug variant of the game and get the backtrace of crashes with gdb",
+				},
+				altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+					Name:    "run.cnf-set",
+					Aliases: []string{"cnf-set"},
+					Usage:   "override a value of default.cnf for the session, e.g. --cnf-set rounds=50",
+				}),
 				altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
 					Name:        "run.players",
 					Aliases:     []string{"players", "p"},
@@ -351,6 +1770,229 @@ func main() {
 			Before: before,
 			Action: run,
 		},
+		{
+			Name:   "match",
+			Usage:  "play a fixed lineup of AIs over many seeds",
+			Before: before,
+			Flags: []cli.Flag{
+				altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+					Name:        "match.players",
+					Aliases:     []string{"players", "p"},
+					Usage:       "set the game players, e.g. -p Dojo -p Dojo:-2 -p Dummy -p Dummy",
+					DefaultText: "<CurrentAi> Dummy Dummy Dummy",
+				}),
+				altsrc.NewStringFlag(&cli.StringFlag{
+					Name:        "match.seeds",
+					Aliases:     []string{"seeds"},
+					Usage:       "seeds of the games, either a range `FROM..TO` or a comma separated list",
+					DefaultText: "1..100",
+					Value:       "1..100",
+				}),
+				altsrc.NewBoolFlag(&cli.BoolFlag{
+					Name:        "match.rotate",
+					Aliases:     []string{"rotate"},
+					Usage:       "rotate the seats of the players on every game",
+					DefaultText: "false",
+					Value:       false,
+				}),
+				altsrc.NewStringFlag(&cli.StringFlag{
+					Name:        "match.selection-seed",
+					Aliases:     []string{"selection-seed"},
+					Usage:       "set the seed used to pick the player versions, either a number or the string 'time'",
+					DefaultText: "time",
+					Value:       "time",
+				}),
+				altsrc.NewIntFlag(&cli.IntFlag{
+					Name:        "match.max-failures",
+					Aliases:     []string{"max-failures"},
+					Usage:       "stop the match when more than `N` games fail (crash, timeout...), -1 means no limit",
+					DefaultText: "10",
+					Value:       10,
+				}),
+			},
+			Action: match,
+		},
+		{
+			Name:      "compare",
+			Usage:     "compare two AIs by playing the same scenarios (seed, opponents and seat) with both",
+			ArgsUsage: "<ai-descriptor> <ai-descriptor>",
+			Before:    before,
+			Flags: []cli.Flag{
+				altsrc.NewIntFlag(&cli.IntFlag{
+					Name:        "compare.games",
+					Aliases:     []string{"games"},
+					Usage:       "number of scenarios, each of them is played once by each AI",
+					DefaultText: "200",
+					Value:       200,
+				}),
+				altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+					Name:        "compare.against",
+					Aliases:     []string{"against"},
+					Usage:       "opponent AIs will be chosen from the pool described by `AI_DESCR`, optionally weighted as AI_DESCR*WEIGHT",
+					DefaultText: "Dummy",
+					Value:       cli.NewStringSlice("Dummy"),
+				}),
+				altsrc.NewStringFlag(&cli.StringFlag{
+					Name:        "compare.selection-seed",
+					Aliases:     []string{"selection-seed"},
+					Usage:       "set the seed used to pick the scenarios, either a number or the string 'time'",
+					DefaultText: "time",
+					Value:       "time",
+				}),
+				altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+					Name:    "compare.cnf-set",
+					Aliases: []string{"cnf-set"},
+					Usage:   "override a value of default.cnf for the session, e.g. --cnf-set rounds=50",
+				}),
+				altsrc.NewStringFlag(&cli.StringFlag{
+					Name:    "compare.sprt",
+					Aliases: []string{"sprt"},
+					Usage:   "stop as soon as a sequential probability ratio test decides whether the first AI is better than the second one, e.g. --sprt elo0=0,elo1=20,alpha=0.05,beta=0.05",
+				}),
+				altsrc.NewIntFlag(&cli.IntFlag{
+					Name:        "compare.max-failures",
+					Aliases:     []string{"max-failures"},
+					Usage:       "stop the comparison when more than `N` games fail (crash, timeout...), -1 means no limit",
+					DefaultText: "10",
+					Value:       10,
+				}),
+			},
+			Action: compare,
+		},
+		{
+			Name:      "ratings",
+			Usage:     "rate the AIs with all the recorded games that are up to date",
+			ArgsUsage: "[ai-descriptor...]",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:        "rating",
+					Usage:       "set the rating system: plackett-luce, elo, glicko2 or trueskill",
+					DefaultText: defaultRating,
+					Value:       defaultRating,
+				},
+			},
+			Action: listRatings,
+		},
+		{
+			Name:      "report",
+			Usage:     "write the report of an evaluation session from its recorded games, or list the sessions",
+			ArgsUsage: "[session-id|last]",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "output",
+					Aliases: []string{"o"},
+					Usage:   "write the report to `FILE`, in HTML, Markdown or CSV depending on its extension (default: <session-id>.html)",
+				},
+			},
+			Action: report,
+		},
+		{
+			Name:      "smoke",
+			Usage:     "play a few short games against Dummy to check that an AI does not crash",
+			ArgsUsage: "[ai-descriptor]",
+			Before:    before,
+			Flags: []cli.Flag{
+				altsrc.NewIntFlag(&cli.IntFlag{
+					Name:        "smoke.games",
+					Aliases:     []string{"games"},
+					Usage:       "number of games, played on the seeds from 1 to `N`",
+					DefaultText: "5",
+					Value:       5,
+				}),
+				altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+					Name:        "smoke.cnf-set",
+					Aliases:     []string{"cnf-set"},
+					Usage:       "override a value of default.cnf for the smoke games",
+					DefaultText: "rounds=50",
+					Value:       cli.NewStringSlice("rounds=50"),
+				}),
+			},
+			Action: smoke,
+		},
+		{
+			Name:      "check-determinism",
+			Usage:     "play every seed twice with the same lineup and seats and report the games that differ",
+			ArgsUsage: "[ai-descriptor]",
+			Before:    before,
+			Flags: []cli.Flag{
+				altsrc.NewIntFlag(&cli.IntFlag{
+					Name:        "check-determinism.seeds",
+					Aliases:     []string{"seeds"},
+					Usage:       "number of seeds to check, from 1 to `N`",
+					DefaultText: "20",
+					Value:       20,
+				}),
+				altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+					Name:        "check-determinism.opponents",
+					Aliases:     []string{"opponents"},
+					Usage:       "set the 3 opponents of the AI, e.g. --opponents Dummy --opponents Dummy --opponents Dojo:-1",
+					DefaultText: "Dummy Dummy Dummy",
+					Value:       cli.NewStringSlice("Dummy", "Dummy", "Dummy"),
+				}),
+			},
+			Action: checkDeterminism,
+		},
+		{
+			Name:      "logs",
+			Usage:     "show the lines written by the players of a game, split by player and round",
+			ArgsUsage: "[game-id|last]",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "player",
+					Usage: "only show the logs of the `PLAYER`, given by its name or seat number",
+				},
+				&cli.IntFlag{
+					Name:  "round",
+					Usage: "only show the logs of the `ROUND`",
+				},
+				&cli.StringFlag{
+					Name:  "grep",
+					Usage: "only show the lines that match the `REGEXP`, prefixed by their player and round",
+				},
+			},
+			Action: logs,
+		},
+		{
+			Name:      "view",
+			Usage:     "open the replay of a game in the game's viewer",
+			ArgsUsage: "[game-id|last]",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "no-open",
+					Usage: "do not open the viewer in the browser, only print its URL",
+				},
+			},
+			Action: view,
+		},
+		{
+			Name:      "rerun",
+			Usage:     "replay exactly a recorded game, with the same seed, players and seats",
+			ArgsUsage: "<game-id|last>",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "print-output",
+					Usage: "print the output from the run command",
+				},
+				&cli.BoolFlag{
+					Name:  "debug",
+					Usage: "run the debug variant of the game and get the backtrace of crashes with gdb",
+				},
+				&cli.StringFlag{
+					Name:  "from-result",
+					Usage: "rerun the game recorded in the `FILE` instead of a game id",
+				},
+				&cli.StringSliceFlag{
+					Name:    "substitute",
+					Aliases: []string{"s"},
+					Usage:   "replace a player by another AI, e.g. -s Dojo_6=Dojo:7, also accepts a seat number: -s 0=Dojo:7",
+				},
+				&cli.BoolFlag{
+					Name:  "timeline",
+					Usage: "plot the score of each player over the rounds",
+				},
+			},
+			Action: rerun,
+		},
 		{
 			Name:   "evaluate",
 			Usage:  "evaluate an AI's performance by playing against other AIs",
@@ -366,10 +2008,115 @@ func main() {
 				altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
 					Name:        "evaluate.against",
 					Aliases:     []string{"against"},
-					Usage:       "opponent AIs will be chosen from the pool described by `AI_DESCR`",
+					Usage:       "opponent AIs will be chosen from the pool described by `AI_DESCR`, optionally weighted as AI_DESCR*WEIGHT",
 					DefaultText: ":",
 					Value:       cli.NewStringSlice(":"),
 				}),
+				altsrc.NewStringFlag(&cli.StringFlag{
+					Name:        "evaluate.selection-seed",
+					Aliases:     []string{"selection-seed"},
+					Usage:       "set the seed used to pick the players, seats and seeds of every game, either a number or the string 'time'",
+					DefaultText: "time",
+					Value:       "time",
+				}),
+				altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+					Name:    "evaluate.cnf-set",
+					Aliases: []string{"cnf-set"},
+					Usage:   "override a value of default.cnf for the session, e.g. --cnf-set rounds=50",
+				}),
+				altsrc.NewBoolFlag(&cli.BoolFlag{
+					Name:        "evaluate.matrix",
+					Aliases:     []string{"matrix"},
+					Usage:       "show the percentage of games each AI finished ahead of each other AI",
+					DefaultText: "false",
+					Value:       false,
+				}),
+				altsrc.NewStringFlag(&cli.StringFlag{
+					Name:    "evaluate.matrix-csv",
+					Aliases: []string{"matrix-csv"},
+					Usage:   "write the head to head matrix to the CSV `FILE`",
+				}),
+				altsrc.NewBoolFlag(&cli.BoolFlag{
+					Name:        "evaluate.rotate",
+					Aliases:     []string{"rotate"},
+					Usage:       "play every sampled lineup and seed in all the rotations of the seats, and report the results by seat",
+					DefaultText: "false",
+					Value:       false,
+				}),
+				altsrc.NewStringFlag(&cli.StringFlag{
+					Name:    "evaluate.sprt",
+					Aliases: []string{"sprt"},
+					Usage:   "stop as soon as a sequential probability ratio test decides whether the evaluated AI is better than the rest, e.g. --sprt elo0=0,elo1=20,alpha=0.05,beta=0.05",
+				}),
+				altsrc.NewStringFlag(&cli.StringFlag{
+					Name:        "evaluate.rating",
+					Aliases:     []string{"rating"},
+					Usage:       "set the rating system of the ranking: plackett-luce, elo, glicko2 or trueskill",
+					DefaultText: defaultRating,
+					Value:       defaultRating,
+				}),
+				altsrc.NewStringFlag(&cli.StringFlag{
+					Name:        "evaluate.scheduler",
+					Aliases:     []string{"scheduler"},
+					Usage:       "set how the players of every game are picked: random, gauntlet (the evaluated AI plays every game), round-robin (every combination of 4 AIs equally often) or balanced (every AI plays the same number of games)",
+					DefaultText: defaultScheduler,
+					Value:       defaultScheduler,
+				}),
+				altsrc.NewDurationFlag(&cli.DurationFlag{
+					Name:    "evaluate.duration",
+					Aliases: []string{"duration"},
+					Usage:   "keep playing games until the time budget is spent instead of playing a number of games, e.g. --duration 20m",
+				}),
+				&cli.StringFlag{
+					Name:  "report",
+					Usage: "write a report of the evaluation to `FILE`, in HTML, Markdown or CSV depending on its extension",
+				},
+				&cli.BoolFlag{
+					Name:  "resume",
+					Usage: "continue the last interrupted evaluation, with the options it was started with",
+				},
+				&cli.StringFlag{
+					Name:  "session",
+					Usage: "resume the session with id `ID` instead of the last interrupted one",
+				},
+				altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+					Name:    "evaluate.pool",
+					Aliases: []string{"pool"},
+					Usage:   "define a named pool of the lineup template, e.g. --pool strong=Dojo:-2..,Dojo_1*2",
+				}),
+				altsrc.NewStringFlag(&cli.StringFlag{
+					Name:    "evaluate.lineup",
+					Aliases: []string{"lineup"},
+					Usage:   "pick the player of every seat from a pool, where me is the evaluated AI and against the --against pool, e.g. --lineup me,strong,weak,weak",
+				}),
+				altsrc.NewBoolFlag(&cli.BoolFlag{
+					Name:        "evaluate.reuse",
+					Aliases:     []string{"reuse"},
+					Usage:       "include in the ranking the games played before by AIs of the pool whose source has not changed",
+					DefaultText: "false",
+					Value:       false,
+				}),
+				altsrc.NewBoolFlag(&cli.BoolFlag{
+					Name:        "evaluate.keep-logs",
+					Aliases:     []string{"keep-logs"},
+					Usage:       "store the lines written by the players of every game, see dojo logs",
+					DefaultText: "false",
+					Value:       false,
+				}),
+				altsrc.NewBoolFlag(&cli.BoolFlag{
+					Name:        "evaluate.timeline",
+					Aliases:     []string{"timeline"},
+					Usage:       "plot the mean score of the best AIs over the rounds",
+					DefaultText: "false",
+					Value:       false,
+				}),
+				altsrc.NewIntFlag(&cli.IntFlag{
+					Name:        "evaluate.max-failures",
+					Aliases:     []string{"max-failures"},
+					Usage:       "stop the evaluation when more than `N` games fail (crash, timeout...), -1 means no limit",
+					DefaultText: "10",
+					Value:       10,
+				}),
 			},
 			Action: evaluate,
 		},
@@ -379,6 +2126,7 @@ func main() {
 		Name:     "dojo",
 		HelpName: "dojo",
 		Usage:    "manage versioning, running and evaluating your EDA game AIs",
+		Version:  version,
 		Before: altsrc.InitInputSourceWithContext(
 			generalFlags,
 			altsrc.NewTomlSourceFromFlagFunc("config"),
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/albertsgrc/dojo/v2/ai"
+	"github.com/albertsgrc/dojo/v2/utils"
+)
+
+// MatchOptions ...
+type MatchOptions struct {
+	Seeds []int64
+	// RotateSeats rotates the seats of the lineup on every game so that each
+	// player plays from every seat
+	RotateSeats   bool
+	Limits        utils.Limits
+	MaxFailures   int
+	SelectionSeed int64
+	// Cnf is the game configuration, default.cnf when nil
+	Cnf *Cnf
+	// Origin is the command that plays the match, recorded with every game
+	Origin string
+}
+
+// MatchPlayerResult ...
+type MatchPlayerResult struct {
+	// Label identifies the player in the lineup, players appearing more than
+	// once are numbered by their position in the lineup
+	Label  string
+	Player string
+	Scores []int
+	// NumGamesAtPlace contains the number of games the player finished in each place
+	NumGamesAtPlace []int
+	// NumGamesAhead contains, for each player of the lineup, the number of
+	// games this player finished with a higher score
+	NumGamesAhead []int
+}
+
+// MatchResult ...
+type MatchResult struct {
+	Players  []*MatchPlayerResult
+	Failures []GameResult
+}
+
+// parseSeeds parses a list of seeds, either a range "1..200" or a comma
+// separated list "1,5,9"
+func parseSeeds(s string) ([]int64, error) {
+	seeds := make([]int64, 0)
+
+	if split := strings.Split(s, ".."); len(split) == 2 {
+		from, errFrom := strconv.ParseInt(split[0], 10, 64)
+		to, errTo := strconv.ParseInt(split[1], 10, 64)
+
+		if errFrom != nil || errTo != nil || from > to {
+			return nil, fmt.Errorf("invalid seed range '%s'", s)
+		}
+
+		for seed := from; seed <= to; seed++ {
+			seeds = append(seeds, seed)
+		}
+
+		return seeds, nil
+	}
+
+	for _, field := range strings.Split(s, ",") {
+		seed, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid seed '%s'", field)
+		}
+
+		seeds = append(seeds, seed)
+	}
+
+	return seeds, nil
+}
+
+// resolveLineup picks a single AI for every descriptor, so that the same
+// versions play all the games
+func resolveLineup(randGenSelection *rand.Rand, playerDescriptors []string) ([]*ai.Ai, error) {
+	lineup := make([]*ai.Ai, len(playerDescriptors))
+
+	for i, descriptor := range playerDescriptors {
+		ais := ai.List(ai.DescriptorFromString(descriptor))
+
+		if len(ais) == 0 {
+			return nil, fmt.Errorf("No AIs found for player %d with descriptor %s", i, descriptor)
+		}
+
+		lineup[i] = ais[randGenSelection.Intn(len(ais))]
+	}
+
+	return lineup, nil
+}
+
+func lineupLabels(lineup []*ai.Ai) []string {
+	count := make(map[string]int)
+	for _, player := range lineup {
+		count[player.PlayerName()]++
+	}
+
+	labels := make([]string, len(lineup))
+	for i, player := range lineup {
+		labels[i] = player.PlayerName()
+
+		if count[player.PlayerName()] > 1 {
+			labels[i] = fmt.Sprintf("%s #%d", player.PlayerName(), i+1)
+		}
+	}
+
+	return labels
+}
+
+type matchGame struct {
+	// seats contains the position in the lineup of the player at each seat
+	seats []int
+	res   gameResultError
+}
+
+func (result *MatchResult) add(seats []int, gameResult GameResult) {
+	for seat, slot := range seats {
+		player := result.Players[slot]
+		score := gameResult.Scores[seat]
+
+		player.Scores = append(player.Scores, score)
+
+		place := 0
+		for otherSeat, otherSlot := range seats {
+			otherScore := gameResult.Scores[otherSeat]
+
+			if otherScore > score {
+				place++
+			} else if otherScore < score {
+				player.NumGamesAhead[otherSlot]++
+			}
+		}
+
+		player.NumGamesAtPlace[place]++
+	}
+}
+
+// Match plays the same lineup on every seed
+func Match(playerDescriptors []string, options MatchOptions, onGameFinished func()) (*MatchResult, error) {
+	if len(playerDescriptors) != 4 {
+		return nil, fmt.Errorf("Invalid number of players '%d'", len(playerDescriptors))
+	}
+
+	randGenSelection := rand.New(rand.NewSource(options.SelectionSeed))
+
+	lineup, err := resolveLineup(randGenSelection, playerDescriptors)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MatchResult{Players: make([]*MatchPlayerResult, len(lineup))}
+
+	for i, label := range lineupLabels(lineup) {
+		result.Players[i] = &MatchPlayerResult{
+			Label:           label,
+			Player:          lineup[i].PlayerName(),
+			Scores:          make([]int, 0, len(options.Seeds)),
+			NumGamesAtPlace: make([]int, len(lineup)),
+			NumGamesAhead:   make([]int, len(lineup)),
+		}
+	}
+
+	games := make(chan matchGame, 200)
+	done := make(chan struct{})
+
+	var stopped int32
+	var matchErr error
+
+	go func() {
+		for game := range games {
+			if game.res.err != nil {
+				if matchErr == nil {
+					matchErr = game.res.err
+				}
+				atomic.StoreInt32(&stopped, 1)
+				continue
+			}
+
+			if game.res.result.Failed() {
+				result.Failures = append(result.Failures, game.res.result)
+
+				if options.MaxFailures >= 0 && len(result.Failures) > options.MaxFailures && matchErr == nil {
+					matchErr = fmt.Errorf("stopped the match because more than %d games failed", options.MaxFailures)
+					atomic.StoreInt32(&stopped, 1)
+				}
+			} else {
+				result.add(game.seats, game.res.result)
+			}
+
+			onGameFinished()
+		}
+
+		close(done)
+	}()
+
+	limit := newGameLimiter()
+
+	for i, seed := range options.Seeds {
+		if atomic.LoadInt32(&stopped) != 0 {
+			break
+		}
+
+		seats := make([]int, len(lineup))
+		descriptors := make([]string, len(lineup))
+
+		for seat := range seats {
+			seats[seat] = seat
+			if options.RotateSeats {
+				seats[seat] = (seat + i) % len(lineup)
+			}
+
+			descriptors[seat] = lineup[seats[seat]].Descriptor()
+		}
+
+		runOptions := RunOptions{
+			Seed:   strconv.FormatInt(seed, 10),
+			Limits: options.Limits,
+			Cnf:    options.Cnf,
+			Origin: options.Origin,
+		}
+
+		limit.Execute(func() {
+			gameResult, err := Run(descriptors, runOptions)
+			games <- matchGame{seats, gameResultError{gameResult, err}}
+		})
+	}
+
+	limit.Wait()
+	close(games)
+	<-done
+
+	return result, matchErr
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSeeds(t *testing.T) {
+	tests := []struct {
+		Seeds    string
+		Expected []int64
+		Valid    bool
+	}{
+		{"1..5", []int64{1, 2, 3, 4, 5}, true},
+		{"7..7", []int64{7}, true},
+		{"-2..1", []int64{-2, -1, 0, 1}, true},
+		{"3", []int64{3}, true},
+		{"3,1, 2", []int64{3, 1, 2}, true},
+		{"5..1", nil, false},
+		{"1..", nil, false},
+		{"..5", nil, false},
+		{"1..2..3", nil, false},
+		{"a..b", nil, false},
+		{"1,x", nil, false},
+		{"", nil, false},
+	}
+
+	for _, test := range tests {
+		seeds, err := parseSeeds(test.Seeds)
+
+		if (err == nil) != test.Valid {
+			t.Error("Found error", err, "parsing", test.Seeds, ", expected valid", test.Valid)
+		} else if test.Valid && !reflect.DeepEqual(seeds, test.Expected) {
+			t.Error("Found", seeds, "parsing", test.Seeds, ", expected", test.Expected)
+		}
+	}
+
+	if seeds, _ := parseSeeds("1..200"); len(seeds) != 200 || seeds[0] != 1 || seeds[199] != 200 {
+		t.Error("Found", len(seeds), "seeds parsing 1..200, expected 200 from 1 to 200")
+	}
+}
+
+func TestMatchResultAdd(t *testing.T) {
+	result := &MatchResult{}
+	for i := 0; i < 4; i++ {
+		result.Players = append(result.Players, &MatchPlayerResult{
+			NumGamesAtPlace: make([]int, 4),
+			NumGamesAhead:   make([]int, 4),
+		})
+	}
+
+	// seats contains the lineup slot of the player of every seat
+	result.add([]int{0, 1, 2, 3}, GameResult{Scores: []int{40, 30, 30, 10}})
+	result.add([]int{1, 2, 3, 0}, GameResult{Scores: []int{50, 20, 10, 60}})
+
+	expected := []struct {
+		Scores          []int
+		NumGamesAtPlace []int
+		NumGamesAhead   []int
+	}{
+		{[]int{40, 60}, []int{2, 0, 0, 0}, []int{0, 2, 2, 2}},
+		{[]int{30, 50}, []int{0, 2, 0, 0}, []int{0, 0, 1, 2}},
+		{[]int{30, 20}, []int{0, 1, 1, 0}, []int{0, 0, 0, 2}},
+		{[]int{10, 10}, []int{0, 0, 0, 2}, []int{0, 0, 0, 0}},
+	}
+
+	for i, player := range result.Players {
+		if !reflect.DeepEqual(player.Scores, expected[i].Scores) ||
+			!reflect.DeepEqual(player.NumGamesAtPlace, expected[i].NumGamesAtPlace) ||
+			!reflect.DeepEqual(player.NumGamesAhead, expected[i].NumGamesAhead) {
+			t.Error("Found", player.Scores, player.NumGamesAtPlace, player.NumGamesAhead, "for slot", i, ", expected", expected[i])
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jedib0t/go-pretty/text"
+)
+
+// Outcome describes how a game finished
+type Outcome string
+
+const (
+	// OutcomeOk means that the game finished and its result could be parsed
+	OutcomeOk Outcome = "ok"
+	// OutcomeCrash means that the game exited with an error outside of a player turn
+	OutcomeCrash Outcome = "crash"
+	// OutcomeTimeout means that the game was killed for exceeding its time limit
+	OutcomeTimeout Outcome = "timeout"
+	// OutcomePlayerAborted means that the game exited with an error during a player turn
+	OutcomePlayerAborted Outcome = "player-aborted"
+	// OutcomeParseFailure means that the game finished but its output could not be parsed
+	OutcomeParseFailure Outcome = "parse-failure"
+)
+
+// Failed ...
+func (gr GameResult) Failed() bool {
+	return gr.Outcome != OutcomeOk
+}
+
+func (gr GameResult) failureString() string {
+	culprit := ""
+	if len(gr.FailedPlayer) > 0 {
+		culprit = " while running " + text.Bold.Sprint(gr.FailedPlayer)
+	}
+
+	switch gr.Outcome {
+	case OutcomeTimeout:
+		return fmt.Sprintf("⏱  Game timed out%s\n", culprit)
+	case OutcomePlayerAborted:
+		return fmt.Sprintf("💥 Game aborted%s\n", culprit)
+	case OutcomeParseFailure:
+		return fmt.Sprintf("❓ Could not parse the game output: %s\n", gr.Error)
+	default:
+		return fmt.Sprintf("💥 Game crashed%s\n", culprit)
+	}
+}
@@ -0,0 +1,55 @@
+package rating
+
+import "math"
+
+// Elo is the Elo rating system applied to every pair of players of a game
+type Elo struct {
+	// K is the maximum change of the rating in each pairwise match
+	K float64
+	// Initial is the rating of new players
+	Initial float64
+	ratings map[string]float64
+}
+
+// NewElo returns an Elo rating system with the parameters dojo has always used
+func NewElo() *Elo {
+	return &Elo{K: 11, Initial: 1500, ratings: make(map[string]float64)}
+}
+
+func (elo *Elo) rating(player string) float64 {
+	if rating, ok := elo.ratings[player]; ok {
+		return rating
+	}
+
+	return elo.Initial
+}
+
+// expectedScore is the probability that a player with rating a beats a player with rating b
+func expectedScore(a float64, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// Update updates the ratings with the result of a game. All the pairwise
+// updates are computed from the ratings before the game, so the order of the
+// players does not matter.
+func (elo *Elo) Update(players []string, scores []int) {
+	deltas := make([]float64, len(players))
+
+	for i := range players {
+		for j := range players {
+			if i != j {
+				expected := expectedScore(elo.rating(players[i]), elo.rating(players[j]))
+				deltas[i] += elo.K * (Outcome(scores[i], scores[j]) - expected)
+			}
+		}
+	}
+
+	for i, player := range players {
+		elo.ratings[player] = elo.rating(player) + deltas[i]
+	}
+}
+
+// Rating returns the rating of the player, Elo does not provide its uncertainty
+func (elo *Elo) Rating(player string) (float64, float64) {
+	return elo.rating(player), 0
+}
@@ -0,0 +1,133 @@
+package rating
+
+import "math"
+
+// glicko2Scale converts ratings and deviations between the Glicko and the
+// Glicko-2 scales
+const glicko2Scale = 173.7178
+
+type glicko2Rating struct {
+	// Mu, Phi and Sigma are the rating, deviation and volatility in the Glicko-2 scale
+	Mu, Phi, Sigma float64
+}
+
+// Glicko2 is the Glicko-2 rating system, where every game is a rating period
+// in which each player plays a match against every other player
+type Glicko2 struct {
+	// Tau constrains the change of the volatility over time
+	Tau     float64
+	ratings map[string]glicko2Rating
+}
+
+// NewGlicko2 returns a Glicko-2 rating system with the usual parameters
+func NewGlicko2() *Glicko2 {
+	return &Glicko2{Tau: 0.5, ratings: make(map[string]glicko2Rating)}
+}
+
+func (glicko *Glicko2) rating(player string) glicko2Rating {
+	if rating, ok := glicko.ratings[player]; ok {
+		return rating
+	}
+
+	return glicko2Rating{Mu: 0, Phi: 350 / glicko2Scale, Sigma: 0.06}
+}
+
+func glicko2G(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func glicko2E(mu float64, opponentMu float64, opponentPhi float64) float64 {
+	return 1 / (1 + math.Exp(-glicko2G(opponentPhi)*(mu-opponentMu)))
+}
+
+// update returns the rating of a player after a rating period with the given
+// opponents and results, following the steps of Glickman's paper
+func (glicko *Glicko2) update(rating glicko2Rating, opponents []glicko2Rating, results []float64) glicko2Rating {
+	if len(opponents) == 0 {
+		rating.Phi = math.Sqrt(rating.Phi*rating.Phi + rating.Sigma*rating.Sigma)
+		return rating
+	}
+
+	inverseV, sum := 0.0, 0.0
+	for i, opponent := range opponents {
+		g := glicko2G(opponent.Phi)
+		e := glicko2E(rating.Mu, opponent.Mu, opponent.Phi)
+
+		inverseV += g * g * e * (1 - e)
+		sum += g * (results[i] - e)
+	}
+
+	v := 1 / inverseV
+	delta := v * sum
+
+	// Find the new volatility with the Illinois algorithm
+	phi2, delta2, tau2 := rating.Phi*rating.Phi, delta*delta, glicko.Tau*glicko.Tau
+	a := math.Log(rating.Sigma * rating.Sigma)
+
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		return ex*(delta2-phi2-v-ex)/(2*(phi2+v+ex)*(phi2+v+ex)) - (x-a)/tau2
+	}
+
+	A, B := a, 0.0
+	if delta2 > phi2+v {
+		B = math.Log(delta2 - phi2 - v)
+	} else {
+		k := 1.0
+		for f(a-k*glicko.Tau) < 0 {
+			k++
+		}
+		B = a - k*glicko.Tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > 1e-6 {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+
+		B, fB = C, fC
+	}
+
+	sigma := math.Exp(A / 2)
+	phiStar := math.Sqrt(phi2 + sigma*sigma)
+	phi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+
+	return glicko2Rating{Mu: rating.Mu + phi*phi*sum, Phi: phi, Sigma: sigma}
+}
+
+// Update updates the ratings with the result of a game, computed from the
+// ratings before the game
+func (glicko *Glicko2) Update(players []string, scores []int) {
+	updated := make([]glicko2Rating, len(players))
+
+	for i, player := range players {
+		opponents := make([]glicko2Rating, 0, len(players)-1)
+		results := make([]float64, 0, len(players)-1)
+
+		for j, opponent := range players {
+			if i != j {
+				opponents = append(opponents, glicko.rating(opponent))
+				results = append(results, Outcome(scores[i], scores[j]))
+			}
+		}
+
+		updated[i] = glicko.update(glicko.rating(player), opponents, results)
+	}
+
+	for i, player := range players {
+		glicko.ratings[player] = updated[i]
+	}
+}
+
+// Rating returns the rating of the player and its deviation, in the Glicko scale
+func (glicko *Glicko2) Rating(player string) (float64, float64) {
+	rating := glicko.rating(player)
+
+	return 1500 + glicko2Scale*rating.Mu, glicko2Scale * rating.Phi
+}
@@ -0,0 +1,245 @@
+package rating
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// game is the result of a game where each player appears once
+type game struct {
+	Players []string
+	Scores  []int
+}
+
+func (g game) key() string {
+	fields := make([]string, len(g.Players))
+	for i, player := range g.Players {
+		fields[i] = fmt.Sprintf("%s:%d", player, g.Scores[i])
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// stage is a step of the Plackett-Luce model of a game, where the winners are
+// chosen among the players that have not been placed yet
+type stage struct {
+	Winners   []int
+	Remaining []int
+}
+
+// PlackettLuce fits the Plackett-Luce model to all the games at once by
+// maximum likelihood, so the ratings do not depend on the order of the games.
+// Players with the same score are handled with Breslow's approximation, and
+// every player has a virtual win and loss against a reference player, which
+// keeps the ratings of players that never win or never lose finite.
+// Confidence intervals are computed by bootstrapping the games.
+type PlackettLuce struct {
+	// NumBootstrap is the number of resamples of the confidence intervals
+	NumBootstrap int
+	// Confidence is the level of the confidence intervals
+	Confidence float64
+	games      []game
+	fitted     bool
+	ratings    map[string]float64
+	intervals  map[string][2]float64
+}
+
+// NewPlackettLuce returns a Plackett-Luce rating with 95% confidence intervals
+func NewPlackettLuce() *PlackettLuce {
+	return &PlackettLuce{NumBootstrap: 200, Confidence: 0.95}
+}
+
+// Update adds the result of a game
+func (pl *PlackettLuce) Update(players []string, scores []int) {
+	pl.games = append(pl.games, game{Players: players, Scores: scores})
+	pl.fitted = false
+}
+
+// Rating returns the rating of the player, in the Elo scale and with a mean
+// of 1500, and the half width of its confidence interval
+func (pl *PlackettLuce) Rating(player string) (float64, float64) {
+	pl.fit()
+
+	interval := pl.intervals[player]
+
+	return pl.ratings[player], (interval[1] - interval[0]) / 2
+}
+
+// Interval returns the confidence interval of the rating of the player
+func (pl *PlackettLuce) Interval(player string) (float64, float64) {
+	pl.fit()
+
+	interval := pl.intervals[player]
+
+	return interval[0], interval[1]
+}
+
+func (pl *PlackettLuce) fit() {
+	if pl.fitted {
+		return
+	}
+
+	// The games are sorted so that the resamples do not depend on their order
+	games := append([]game{}, pl.games...)
+	sort.SliceStable(games, func(i, j int) bool {
+		return games[i].key() < games[j].key()
+	})
+
+	pl.ratings = fitPlackettLuce(games)
+	pl.intervals = make(map[string][2]float64)
+
+	randGen := rand.New(rand.NewSource(1))
+	samples := make(map[string][]float64)
+
+	for b := 0; b < pl.NumBootstrap && len(games) > 0; b++ {
+		resample := make([]game, len(games))
+		for i := range resample {
+			resample[i] = games[randGen.Intn(len(games))]
+		}
+
+		for player, rating := range fitPlackettLuce(resample) {
+			samples[player] = append(samples[player], rating)
+		}
+	}
+
+	for player, rating := range pl.ratings {
+		values := samples[player]
+
+		if len(values) == 0 {
+			pl.intervals[player] = [2]float64{rating, rating}
+			continue
+		}
+
+		sort.Float64s(values)
+		alpha := (1 - pl.Confidence) / 2
+		low := values[int(math.Floor(alpha*float64(len(values)-1)))]
+		high := values[int(math.Ceil((1-alpha)*float64(len(values)-1)))]
+
+		pl.intervals[player] = [2]float64{low, high}
+	}
+
+	pl.fitted = true
+}
+
+// stages splits the game into the stages of the Plackett-Luce model, with
+// the players identified by their index in indices
+func (g game) stages(indices map[string]int) []stage {
+	order := make([]int, len(g.Players))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return g.Scores[order[i]] > g.Scores[order[j]]
+	})
+
+	stages := make([]stage, 0)
+
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && g.Scores[order[end]] == g.Scores[order[start]] {
+			end++
+		}
+
+		// The players of the last place are not chosen among anyone else
+		if end < len(order) {
+			s := stage{}
+			for _, i := range order[start:end] {
+				s.Winners = append(s.Winners, indices[g.Players[i]])
+			}
+			for _, i := range order[start:] {
+				s.Remaining = append(s.Remaining, indices[g.Players[i]])
+			}
+
+			stages = append(stages, s)
+		}
+
+		start = end
+	}
+
+	return stages
+}
+
+// fitPlackettLuce returns the maximum likelihood ratings of the players of
+// the games, found with Hunter's minorization-maximization algorithm
+func fitPlackettLuce(games []game) map[string]float64 {
+	indices := make(map[string]int)
+	players := make([]string, 0)
+
+	for _, g := range games {
+		for _, player := range g.Players {
+			if _, ok := indices[player]; !ok {
+				indices[player] = len(players)
+				players = append(players, player)
+			}
+		}
+	}
+
+	stages := make([]stage, 0)
+	for _, g := range games {
+		stages = append(stages, g.stages(indices)...)
+	}
+
+	// Every player starts with the virtual win against the reference player
+	wins := make([]float64, len(players))
+	for i := range wins {
+		wins[i] = 1
+	}
+
+	for _, s := range stages {
+		for _, winner := range s.Winners {
+			wins[winner]++
+		}
+	}
+
+	strengths := make([]float64, len(players))
+	for i := range strengths {
+		strengths[i] = 1
+	}
+
+	denominators := make([]float64, len(players))
+
+	for iteration := 0; iteration < 1000; iteration++ {
+		for i, strength := range strengths {
+			// The virtual win and loss against the reference player of strength 1
+			denominators[i] = 2 / (strength + 1)
+		}
+
+		for _, s := range stages {
+			total := 0.0
+			for _, player := range s.Remaining {
+				total += strengths[player]
+			}
+
+			for _, player := range s.Remaining {
+				denominators[player] += float64(len(s.Winners)) / total
+			}
+		}
+
+		change := 0.0
+		for i := range strengths {
+			updated := wins[i] / denominators[i]
+			change = math.Max(change, math.Abs(math.Log(updated/strengths[i])))
+			strengths[i] = updated
+		}
+
+		if change < 1e-9 {
+			break
+		}
+	}
+
+	meanLog := 0.0
+	for _, strength := range strengths {
+		meanLog += math.Log10(strength)
+	}
+	meanLog /= float64(len(strengths))
+
+	ratings := make(map[string]float64)
+	for i, player := range players {
+		ratings[player] = 1500 + 400*(math.Log10(strengths[i])-meanLog)
+	}
+
+	return ratings
+}
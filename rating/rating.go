@@ -0,0 +1,15 @@
+// Package rating implements rating systems for multiplayer games, where the
+// result of a game is the score of each player
+package rating
+
+// Outcome returns the result of a player with the given score against an
+// opponent: 1 for a win, 0.5 for a tie and 0 for a loss
+func Outcome(score int, opponentScore int) float64 {
+	if score > opponentScore {
+		return 1
+	} else if score == opponentScore {
+		return 0.5
+	}
+
+	return 0
+}
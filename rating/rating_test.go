@@ -0,0 +1,129 @@
+package rating
+
+import (
+	"math"
+	"testing"
+)
+
+func TestElo(t *testing.T) {
+	elo := NewElo()
+	elo.Update([]string{"A", "B", "C", "D"}, []int{40, 30, 30, 10})
+
+	expected := map[string]float64{"A": 1516.5, "B": 1500, "C": 1500, "D": 1483.5}
+
+	for player, rating := range expected {
+		if actual, _ := elo.Rating(player); math.Abs(actual-rating) > 1e-9 {
+			t.Error("Found rating", actual, "for", player, ", expected", rating)
+		}
+	}
+}
+
+func TestGlicko2(t *testing.T) {
+	// Example from Glickman's paper "Example of the Glicko-2 system"
+	glicko := NewGlicko2()
+	rating := glicko2Rating{Mu: 0, Phi: 200 / glicko2Scale, Sigma: 0.06}
+	opponents := []glicko2Rating{
+		{Mu: (1400 - 1500) / glicko2Scale, Phi: 30 / glicko2Scale},
+		{Mu: (1550 - 1500) / glicko2Scale, Phi: 100 / glicko2Scale},
+		{Mu: (1700 - 1500) / glicko2Scale, Phi: 300 / glicko2Scale},
+	}
+
+	updated := glicko.update(rating, opponents, []float64{1, 0, 0})
+
+	if r := 1500 + glicko2Scale*updated.Mu; math.Abs(r-1464.06) > 0.01 {
+		t.Error("Found rating", r, ", expected 1464.06")
+	}
+
+	if rd := glicko2Scale * updated.Phi; math.Abs(rd-151.52) > 0.01 {
+		t.Error("Found deviation", rd, ", expected 151.52")
+	}
+
+	if math.Abs(updated.Sigma-0.05999) > 1e-5 {
+		t.Error("Found volatility", updated.Sigma, ", expected 0.05999")
+	}
+}
+
+func TestTrueSkill(t *testing.T) {
+	// Reference values of the original TrueSkill implementation
+	tests := []struct {
+		Scores   []int
+		Expected [][2]float64
+	}{
+		{[]int{1, 0}, [][2]float64{{29.396, 7.171}, {20.604, 7.171}}},
+		{[]int{0, 0}, [][2]float64{{25.000, 6.458}, {25.000, 6.458}}},
+		{[]int{3, 2, 1, 0}, [][2]float64{{33.207, 6.348}, {27.401, 5.787}, {22.599, 5.787}, {16.793, 6.348}}},
+	}
+
+	players := []string{"A", "B", "C", "D"}
+
+	for _, test := range tests {
+		trueSkill := NewTrueSkill()
+		trueSkill.Update(players[:len(test.Scores)], test.Scores)
+
+		for i, expected := range test.Expected {
+			mu, sigma := trueSkill.Rating(players[i])
+
+			if math.Abs(mu-expected[0]) > 1e-3 || math.Abs(sigma-expected[1]) > 1e-3 {
+				t.Error("Found", mu, sigma, "for", players[i], "with scores", test.Scores, ", expected", expected)
+			}
+		}
+	}
+}
+
+func TestPlackettLuce(t *testing.T) {
+	games := [][]int{{40, 30, 20, 10}, {40, 20, 30, 10}, {30, 40, 10, 20}, {40, 30, 10, 10}}
+	players := []string{"A", "B", "C", "D"}
+
+	pl := NewPlackettLuce()
+	reversed := NewPlackettLuce()
+
+	for i := range games {
+		pl.Update(players, games[i])
+		reversed.Update(players, games[len(games)-1-i])
+	}
+
+	previous := math.Inf(1)
+	for _, player := range players {
+		rating, _ := pl.Rating(player)
+		low, high := pl.Interval(player)
+
+		if rating >= previous {
+			t.Error("Found rating", rating, "for", player, ", expected less than", previous)
+		}
+
+		if low > rating || high < rating {
+			t.Error("Found interval", low, high, "for", player, "with rating", rating)
+		}
+
+		if reversedRating, _ := reversed.Rating(player); reversedRating != rating {
+			t.Error("Found rating", reversedRating, "for", player, "with the games reversed, expected", rating)
+		}
+
+		reversedLow, reversedHigh := reversed.Interval(player)
+		if reversedLow != low || reversedHigh != high {
+			t.Error("Found interval", reversedLow, reversedHigh, "for", player, "with the games reversed, expected", low, high)
+		}
+
+		previous = rating
+	}
+}
+
+func TestFitPlackettLuceTwoPlayers(t *testing.T) {
+	// With two players Plackett-Luce is Bradley-Terry: A wins 3 of 4 games,
+	// plus the virtual games against the reference player
+	games := []game{
+		{[]string{"A", "B"}, []int{1, 0}},
+		{[]string{"A", "B"}, []int{1, 0}},
+		{[]string{"A", "B"}, []int{1, 0}},
+		{[]string{"A", "B"}, []int{0, 1}},
+	}
+
+	ratings := fitPlackettLuce(games)
+
+	// Solved numerically from the likelihood equations, without the virtual
+	// games the difference would be 400*log10(3) = 190.85
+	difference := ratings["A"] - ratings["B"]
+	if math.Abs(ratings["A"]+ratings["B"]-3000) > 1e-6 || math.Abs(difference-145.790) > 1e-3 {
+		t.Error("Found ratings", ratings)
+	}
+}
@@ -0,0 +1,248 @@
+package rating
+
+import (
+	"math"
+	"sort"
+)
+
+// gaussian is a normal distribution in natural parameters, which makes
+// multiplying and dividing messages simple. A zero precision is a uniform
+// distribution.
+type gaussian struct {
+	// Pi is the precision, 1/σ², and Tau the precision adjusted mean, μ/σ²
+	Pi, Tau float64
+}
+
+func newGaussian(mean float64, variance float64) gaussian {
+	return gaussian{Pi: 1 / variance, Tau: mean / variance}
+}
+
+func (g gaussian) mean() float64 {
+	if g.Pi == 0 {
+		return 0
+	}
+
+	return g.Tau / g.Pi
+}
+
+func (g gaussian) variance() float64 {
+	return 1 / g.Pi
+}
+
+func (g gaussian) mul(other gaussian) gaussian {
+	return gaussian{Pi: g.Pi + other.Pi, Tau: g.Tau + other.Tau}
+}
+
+func (g gaussian) div(other gaussian) gaussian {
+	return gaussian{Pi: g.Pi - other.Pi, Tau: g.Tau - other.Tau}
+}
+
+// sum returns the distribution of a+b if sign is 1, or a-b if sign is -1,
+// which is uniform if any of them is
+func sum(a gaussian, b gaussian, sign float64) gaussian {
+	if a.Pi == 0 || b.Pi == 0 {
+		return gaussian{}
+	}
+
+	return newGaussian(a.mean()+sign*b.mean(), a.variance()+b.variance())
+}
+
+func pdf(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
+
+func cdf(x float64) float64 {
+	return math.Erfc(-x/math.Sqrt2) / 2
+}
+
+func inverseCdf(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// vWin and wWin correct the mean and variance of a difference that is known
+// to be greater than epsilon
+func vWin(t float64, epsilon float64) float64 {
+	denominator := cdf(t - epsilon)
+	if denominator < 1e-300 {
+		return epsilon - t
+	}
+
+	return pdf(t-epsilon) / denominator
+}
+
+func wWin(t float64, epsilon float64) float64 {
+	v := vWin(t, epsilon)
+
+	return v * (v + t - epsilon)
+}
+
+// vDraw and wDraw correct the mean and variance of a difference that is
+// known to be within [-epsilon, epsilon]
+func vDraw(t float64, epsilon float64) float64 {
+	denominator := cdf(epsilon-t) - cdf(-epsilon-t)
+	if denominator < 1e-300 {
+		if t < 0 {
+			return -t - epsilon
+		}
+
+		return -t + epsilon
+	}
+
+	return (pdf(-epsilon-t) - pdf(epsilon-t)) / denominator
+}
+
+func wDraw(t float64, epsilon float64) float64 {
+	denominator := cdf(epsilon-t) - cdf(-epsilon-t)
+	if denominator < 1e-300 {
+		return 1
+	}
+
+	v := vDraw(t, epsilon)
+
+	return v*v + ((epsilon-t)*pdf(epsilon-t)+(epsilon+t)*pdf(epsilon+t))/denominator
+}
+
+// TrueSkill is the TrueSkill rating system for free-for-all games, where
+// the players are ranked by their score and equal scores are draws
+type TrueSkill struct {
+	// Mu and Sigma are the rating and uncertainty of new players
+	Mu, Sigma float64
+	// Beta is the variance of the performance of a player in a game
+	Beta float64
+	// Tau is the increase of the uncertainty of a player before every game
+	Tau float64
+	// DrawProbability is the probability of a draw between two players
+	DrawProbability float64
+	ratings         map[string]gaussian
+}
+
+// NewTrueSkill returns a TrueSkill rating system with the usual parameters
+func NewTrueSkill() *TrueSkill {
+	return &TrueSkill{
+		Mu:              25,
+		Sigma:           25.0 / 3,
+		Beta:            25.0 / 6,
+		Tau:             25.0 / 300,
+		DrawProbability: 0.1,
+		ratings:         make(map[string]gaussian),
+	}
+}
+
+func (trueSkill *TrueSkill) rating(player string) gaussian {
+	if rating, ok := trueSkill.ratings[player]; ok {
+		return rating
+	}
+
+	return newGaussian(trueSkill.Mu, trueSkill.Sigma*trueSkill.Sigma)
+}
+
+// Update updates the ratings with the result of a game, running expectation
+// propagation on the factor graph of the ranking until it converges
+func (trueSkill *TrueSkill) Update(players []string, scores []int) {
+	n := len(players)
+	if n < 2 {
+		return
+	}
+
+	// Sort the players by place
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return scores[order[i]] > scores[order[j]]
+	})
+
+	epsilon := inverseCdf((trueSkill.DrawProbability+1)/2) * math.Sqrt2 * trueSkill.Beta
+
+	// skills contains the prior skill of each player, and performances the
+	// message from the skill to the performance of each player
+	skills := make([]gaussian, n)
+	performances := make([]gaussian, n)
+
+	for place, player := range order {
+		rating := trueSkill.rating(players[player])
+		skills[place] = newGaussian(rating.mean(), rating.variance()+trueSkill.Tau*trueSkill.Tau)
+		performances[place] = newGaussian(skills[place].mean(), skills[place].variance()+trueSkill.Beta*trueSkill.Beta)
+	}
+
+	// Messages from the difference factor between places k and k+1 to the
+	// performance of each of them, and from the truncation factor to the difference
+	toLeft := make([]gaussian, n-1)
+	toRight := make([]gaussian, n-1)
+	truncations := make([]gaussian, n-1)
+
+	marginal := func(place int) gaussian {
+		result := performances[place]
+
+		if place > 0 {
+			result = result.mul(toRight[place-1])
+		}
+
+		if place < n-1 {
+			result = result.mul(toLeft[place])
+		}
+
+		return result
+	}
+
+	updateDifference := func(k int) float64 {
+		left := marginal(k).div(toLeft[k])
+		right := marginal(k + 1).div(toRight[k])
+
+		difference := sum(left, right, -1)
+		mean, stdev := difference.mean(), math.Sqrt(difference.variance())
+
+		var v, w float64
+		if scores[order[k]] == scores[order[k+1]] {
+			v, w = vDraw(mean/stdev, epsilon/stdev), wDraw(mean/stdev, epsilon/stdev)
+		} else {
+			v, w = vWin(mean/stdev, epsilon/stdev), wWin(mean/stdev, epsilon/stdev)
+		}
+
+		truncated := newGaussian(mean+stdev*v, difference.variance()*(1-w))
+		truncation := truncated.div(difference)
+		change := math.Abs(truncation.Tau-truncations[k].Tau) + math.Abs(truncation.Pi-truncations[k].Pi)
+		truncations[k] = truncation
+
+		toLeft[k] = sum(truncation, right, 1)
+		toRight[k] = sum(left, truncation, -1)
+
+		return change
+	}
+
+	for iteration := 0; iteration < 20; iteration++ {
+		change := 0.0
+
+		for k := 0; k < n-1; k++ {
+			change = math.Max(change, updateDifference(k))
+		}
+
+		for k := n - 3; k >= 0; k-- {
+			change = math.Max(change, updateDifference(k))
+		}
+
+		if change < 1e-6 {
+			break
+		}
+	}
+
+	for place, player := range order {
+		toPerformance := marginal(place).div(performances[place])
+
+		if toPerformance.Pi == 0 {
+			trueSkill.ratings[players[player]] = skills[place]
+			continue
+		}
+
+		toSkill := newGaussian(toPerformance.mean(), toPerformance.variance()+trueSkill.Beta*trueSkill.Beta)
+		trueSkill.ratings[players[player]] = skills[place].mul(toSkill)
+	}
+}
+
+// Rating returns the mean skill of the player and its standard deviation
+func (trueSkill *TrueSkill) Rating(player string) (float64, float64) {
+	rating := trueSkill.rating(player)
+
+	return rating.mean(), math.Sqrt(rating.variance())
+}
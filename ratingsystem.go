@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/albertsgrc/dojo/v2/rating"
+)
+
+// RatingSystem rates the players from the results of the games they play
+type RatingSystem interface {
+	// Update updates the ratings with the scores of the players of a game,
+	// each player must appear only once
+	Update(players []string, scores []int)
+	// Rating returns the rating of a player and its uncertainty, which is 0
+	// if the system does not provide it
+	Rating(player string) (float64, float64)
+}
+
+// intervalRatingSystem is a rating system that provides confidence intervals
+type intervalRatingSystem interface {
+	Interval(player string) (float64, float64)
+}
+
+// defaultRating is the rating system used when none is chosen
+const defaultRating = "plackett-luce"
+
+// ratingSystems contains the constructor of every rating system by its name
+var ratingSystems = map[string]func() RatingSystem{
+	"elo":           func() RatingSystem { return rating.NewElo() },
+	"glicko2":       func() RatingSystem { return rating.NewGlicko2() },
+	"trueskill":     func() RatingSystem { return rating.NewTrueSkill() },
+	"plackett-luce": func() RatingSystem { return rating.NewPlackettLuce() },
+}
+
+// newRatingSystem returns the rating system with the given name
+func newRatingSystem(name string) (RatingSystem, error) {
+	newSystem, ok := ratingSystems[name]
+
+	if !ok {
+		return nil, fmt.Errorf("unknown rating system '%s', use plackett-luce, elo, glicko2 or trueskill", name)
+	}
+
+	return newSystem(), nil
+}
+
+// ratedScores returns the players of a game, sorted by name, and their scores
+// given the best score of each player, since rating systems expect every
+// player to appear once
+func ratedScores(scores map[string]int) ([]string, []int) {
+	players := make([]string, 0, len(scores))
+	for player := range scores {
+		players = append(players, player)
+	}
+	sort.Strings(players)
+
+	playerScores := make([]int, len(players))
+	for i, player := range players {
+		playerScores[i] = scores[player]
+	}
+
+	return players, playerScores
+}
+
+// gameScores returns the best score of each player of a game
+func gameScores(gameResult GameResult) map[string]int {
+	scores := make(map[string]int)
+
+	for i, player := range gameResult.Players {
+		if score, ok := scores[player]; !ok || gameResult.Scores[i] > score {
+			scores[player] = gameResult.Scores[i]
+		}
+	}
+
+	return scores
+}
+
+// ratingString formats a rating with its confidence interval or its uncertainty
+func ratingString(value float64, deviation float64, interval []float64) string {
+	if len(interval) == 2 {
+		return fmt.Sprintf("%.1f (%.1f..%.1f)", value, interval[0], interval[1])
+	} else if deviation > 0 {
+		return fmt.Sprintf("%.1f ± %.1f", value, deviation)
+	}
+
+	return fmt.Sprintf("%.1f", value)
+}
@@ -0,0 +1,175 @@
+package replay
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// Round ...
+type Round struct {
+	Number int
+	// Scores contains the score of every player at the end of the round
+	Scores []int
+	// Status contains the fraction of its time budget that every player has
+	// used at the end of the round, or -1 if the player has been disqualified
+	Status []float64
+	// Content contains all the lines of the round
+	Content []string
+}
+
+// Replay contains the per-round information of a game .res file
+type Replay struct {
+	// Header contains the lines before the first round
+	Header []string
+	Rounds []Round
+}
+
+// Parse parses the content of a .res file. Rounds start with a "round N" line
+// and contain a "score" line with the score of each player.
+func Parse(content string) Replay {
+	replay := Replay{Rounds: make([]Round, 0)}
+
+	var round *Round
+
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) == 2 && fields[0] == "round" {
+			if number, err := strconv.Atoi(fields[1]); err == nil {
+				replay.Rounds = append(replay.Rounds, Round{Number: number, Content: []string{line}})
+				round = &replay.Rounds[len(replay.Rounds)-1]
+				continue
+			}
+		}
+
+		if round == nil {
+			replay.Header = append(replay.Header, line)
+			continue
+		}
+
+		round.Content = append(round.Content, line)
+
+		switch fields[0] {
+		case "score":
+			round.Scores = make([]int, 0, len(fields)-1)
+			for _, field := range fields[1:] {
+				score, _ := strconv.Atoi(field)
+				round.Scores = append(round.Scores, score)
+			}
+
+		case "status":
+			round.Status = make([]float64, 0, len(fields)-1)
+			for _, field := range fields[1:] {
+				status, _ := strconv.ParseFloat(field, 64)
+				round.Status = append(round.Status, status)
+			}
+		}
+	}
+
+	return replay
+}
+
+// ParseFile ...
+func ParseFile(fileName string) (Replay, error) {
+	content, err := ioutil.ReadFile(fileName)
+
+	if err != nil {
+		return Replay{}, err
+	}
+
+	return Parse(string(content)), nil
+}
+
+// Timeline returns the score of each player over the rounds, indexed first
+// by player and then by round
+func (r Replay) Timeline() [][]int {
+	timeline := make([][]int, 0)
+
+	for _, round := range r.Rounds {
+		for len(timeline) < len(round.Scores) {
+			timeline = append(timeline, make([]int, 0, len(r.Rounds)))
+		}
+
+		for player, score := range round.Scores {
+			timeline[player] = append(timeline[player], score)
+		}
+	}
+
+	return timeline
+}
+
+// CPU returns the fraction of its time budget that every player used in the
+// game, or -1 for the players that were disqualified for exceeding it. It
+// returns nil if the replay has no status information.
+func (r Replay) CPU() []float64 {
+	var cpu []float64
+
+	for _, round := range r.Rounds {
+		for len(cpu) < len(round.Status) {
+			cpu = append(cpu, 0)
+		}
+
+		for player, status := range round.Status {
+			if cpu[player] >= 0 {
+				cpu[player] = status
+			}
+		}
+	}
+
+	return cpu
+}
+
+// FirstDifference returns the index of the first round whose content differs
+// between both replays, or -1 if the header differs. Status lines are ignored,
+// since the time used by the players changes between runs. Replays with a
+// different number of rounds differ at the first round missing in one of
+// them. The second value is false if both replays are equal.
+func (r Replay) FirstDifference(other Replay) (int, bool) {
+	if !equalLines(r.Header, other.Header) {
+		return -1, true
+	}
+
+	for i := range r.Rounds {
+		if i >= len(other.Rounds) || !equalLines(withoutStatus(r.Rounds[i].Content), withoutStatus(other.Rounds[i].Content)) {
+			return i, true
+		}
+	}
+
+	if len(other.Rounds) > len(r.Rounds) {
+		return len(r.Rounds), true
+	}
+
+	return 0, false
+}
+
+// withoutStatus returns the lines of a round except its status line
+func withoutStatus(lines []string) []string {
+	filtered := make([]string, 0, len(lines))
+
+	for _, line := range lines {
+		if fields := strings.Fields(line); len(fields) == 0 || fields[0] != "status" {
+			filtered = append(filtered, line)
+		}
+	}
+
+	return filtered
+}
+
+func equalLines(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
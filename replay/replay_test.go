@@ -0,0 +1,85 @@
+package replay
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const res = `Moria v1
+
+nb_players 4
+nb_rounds 3
+
+round 0
+score 0 0 0 0
+status 0 0 0 0
+
+round 1
+..M.
+score 1 0 2 0
+status 0.1 0 0 0
+
+round 2
+score 5 0 3 1
+status 0.2 0 0 -1
+`
+
+func TestParse(t *testing.T) {
+	replay := Parse(res)
+
+	if len(replay.Rounds) != 3 {
+		t.Fatal("Found", len(replay.Rounds), "rounds, expected 3")
+	}
+
+	if replay.Rounds[2].Number != 2 {
+		t.Error("Found round number", replay.Rounds[2].Number, ", expected 2")
+	}
+
+	if !reflect.DeepEqual(replay.Rounds[1].Scores, []int{1, 0, 2, 0}) {
+		t.Error("Found scores", replay.Rounds[1].Scores, ", expected [1 0 2 0]")
+	}
+}
+
+func TestTimeline(t *testing.T) {
+	timeline := Parse(res).Timeline()
+
+	expected := [][]int{{0, 1, 5}, {0, 0, 0}, {0, 2, 3}, {0, 0, 1}}
+
+	if !reflect.DeepEqual(timeline, expected) {
+		t.Error("Found timeline", timeline, ", expected", expected)
+	}
+}
+
+func TestCPU(t *testing.T) {
+	cpu := Parse(res).CPU()
+
+	expected := []float64{0.2, 0, 0, -1}
+
+	if !reflect.DeepEqual(cpu, expected) {
+		t.Error("Found cpu", cpu, ", expected", expected)
+	}
+}
+
+func TestFirstDifference(t *testing.T) {
+	tests := []struct {
+		Other    string
+		Expected int
+		Differ   bool
+	}{
+		{res, 0, false},
+		{strings.Replace(res, "..M.", "..M#", 1), 1, true},
+		{strings.Replace(res, "nb_rounds 3", "nb_rounds 4", 1), -1, true},
+		{res[:strings.Index(res, "round 2")], 2, true},
+		{res + "round 3\nscore 0 0 0 0\n", 3, true},
+		{strings.Replace(res, "status 0.2 0 0 -1", "status 0.3 0 0 -1", 1), 0, false},
+	}
+
+	for _, test := range tests {
+		difference, differ := Parse(res).FirstDifference(Parse(test.Other))
+
+		if difference != test.Expected || differ != test.Differ {
+			t.Error("Found difference at", difference, differ, ", expected", test.Expected, test.Differ)
+		}
+	}
+}
@@ -0,0 +1,752 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/montanaflynn/stats"
+)
+
+// numHistogramBins is the number of bins of the score distributions of a report
+const numHistogramBins = 10
+
+// Report contains everything written to an evaluation report
+type Report struct {
+	Session    *Session
+	Evaluation *Evaluation
+	// Games contains the games of the session, in the order they finished
+	Games []GameResult
+	// Generated is the time the report was written
+	Generated time.Time
+}
+
+// sessionEvaluation ranks the AIs of a session from its recorded games,
+// without playing any new one
+func sessionEvaluation(session *Session) (*Evaluation, []GameResult, error) {
+	options := session.Options
+
+	ratingName := options.Rating
+	if len(ratingName) == 0 {
+		ratingName = defaultRating
+	}
+
+	ratings, err := newRatingSystem(ratingName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if options.SPRT != nil {
+		sprt := *options.SPRT
+		sprt.LLRs = nil
+		sprt.Decision = SPRTContinue
+		options.SPRT = &sprt
+	}
+
+	aiToResults := make(map[string]*aiResults)
+	evaluation := &Evaluation{SelectionSeed: options.SelectionSeed, SPRT: options.SPRT}
+
+	for _, id := range session.Reused {
+		gameResult, err := loadGameResult(id)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		processResult(session.Player, gameResult, aiToResults, ratings)
+		evaluation.NumReused++
+	}
+
+	games, err := sessionGames(session)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, gameResult := range games {
+		// The failures are all listed, so stopping at too many is irrelevant
+		addGameResult(evaluation, session.Player, gameResult, aiToResults, ratings, options)
+	}
+
+	evaluation.Ranking = rankResults(aiToResults, ratings)
+	evaluation.Interrupted = session.Status == SessionInterrupted
+
+	return evaluation, games, nil
+}
+
+// sessionGames loads the games played in a session, in the order they finished
+func sessionGames(session *Session) ([]GameResult, error) {
+	games := make([]GameResult, 0, len(session.Games))
+
+	for _, index := range session.PlayedIndices() {
+		gameResult, err := loadGameResult(session.Games[index])
+		if err != nil {
+			return nil, err
+		}
+
+		games = append(games, gameResult)
+	}
+
+	return games, nil
+}
+
+// loadReport loads the session with the given id, or the last one if id is
+// "last", and ranks its AIs
+func loadReport(id string) (*Report, error) {
+	if id == "last" {
+		ids, err := sessionIDs()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("no evaluation sessions have been recorded yet")
+		}
+
+		id = ids[len(ids)-1]
+	}
+
+	session, err := loadSession(id)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluation, games, err := sessionEvaluation(session)
+	if err != nil {
+		return nil, fmt.Errorf("could not load the games of session %s: %v", id, err)
+	}
+
+	return &Report{Session: session, Evaluation: evaluation, Games: games, Generated: time.Now()}, nil
+}
+
+// reportWriters contains the function that writes a report in every format
+// by the extension of the file
+var reportWriters = map[string]func(report *Report, file *os.File) error{
+	".html":     (*Report).writeHTML,
+	".htm":      (*Report).writeHTML,
+	".md":       (*Report).writeMarkdown,
+	".markdown": (*Report).writeMarkdown,
+	".csv":      (*Report).writeCSV,
+}
+
+// checkReportFile returns an error if the format of a report file is unknown
+func checkReportFile(fileName string) error {
+	if _, ok := reportWriters[strings.ToLower(filepath.Ext(fileName))]; !ok {
+		return fmt.Errorf("unknown report format of '%s', use a .html, .md or .csv file", fileName)
+	}
+
+	return nil
+}
+
+// Write writes the report to a file, in HTML, Markdown or CSV depending on
+// its extension
+func (report *Report) Write(fileName string) error {
+	if err := checkReportFile(fileName); err != nil {
+		return err
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	if err := reportWriters[strings.ToLower(filepath.Ext(fileName))](report, file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// reportTable is a table of a report, with its header and rows
+type reportTable struct {
+	Header []string
+	Rows   [][]string
+}
+
+// metadata returns the name and value of every property of the session
+func (report *Report) metadata() [][2]string {
+	session := report.Session
+	options := session.Options
+
+	numFailed := len(report.Evaluation.Failures)
+	played := fmt.Sprintf("%d", len(report.Games))
+	if options.Duration > 0 {
+		played += fmt.Sprintf(" in %s of %s", session.Elapsed.Round(time.Second), options.Duration)
+	} else {
+		played += fmt.Sprintf(" of %d", options.TotalGames())
+	}
+
+	metadata := [][2]string{
+		{"Session", session.ID},
+		{"Evaluated AI", session.Player},
+		{"Status", string(session.Status)},
+		{"Started", session.Time.Format(time.RFC3339)},
+		{"Games", played},
+		{"Failed games", strconv.Itoa(numFailed)},
+		{"Reused games", strconv.Itoa(len(session.Reused))},
+		{"Selection seed", strconv.FormatInt(options.SelectionSeed, 10)},
+		{"Against", strings.Join(options.Against, " ")},
+	}
+
+	if len(options.Lineup) > 0 {
+		names := make([]string, 0, len(options.Pools))
+		for name := range options.Pools {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		pools := make([]string, len(names))
+		for i, name := range names {
+			pools[i] = name + "=" + strings.Join(options.Pools[name], ",")
+		}
+
+		metadata = append(metadata,
+			[2]string{"Lineup", strings.Join(options.Lineup, ",")},
+			[2]string{"Pools", strings.Join(pools, " ")})
+	} else {
+		scheduler := options.Scheduler
+		if len(scheduler) == 0 {
+			scheduler = defaultScheduler
+		}
+
+		metadata = append(metadata, [2]string{"Scheduler", scheduler})
+	}
+
+	rating := options.Rating
+	if len(rating) == 0 {
+		rating = defaultRating
+	}
+
+	overrides := "-"
+	if options.Cnf != nil && len(options.Cnf.Overrides) > 0 {
+		overrides = strings.Join(options.Cnf.Overrides, " ")
+	}
+
+	metadata = append(metadata,
+		[2]string{"Rotate seats", strconv.FormatBool(options.RotateSeats)},
+		[2]string{"Rating system", rating},
+		[2]string{"Configuration overrides", overrides},
+		[2]string{"Configuration hash", strings.Join(report.cnfHashes(), " ")},
+		[2]string{"Limits", fmt.Sprintf("timeout %s, memory %d MB, CPU %d s",
+			options.Limits.Timeout, options.Limits.MemoryMB, options.Limits.CPUSeconds)},
+	)
+
+	if report.Evaluation.SPRT != nil {
+		metadata = append(metadata, [2]string{"SPRT", report.Evaluation.SPRT.String()})
+	}
+
+	for _, hashes := range report.versions() {
+		metadata = append(metadata, [2]string{"Source hash of " + hashes[0], hashes[1]})
+	}
+
+	return append(metadata,
+		[2]string{"Dojo version", version},
+		[2]string{"Generated", report.Generated.Format(time.RFC3339)})
+}
+
+// cnfHashes returns the different hashes of the configuration of the games
+func (report *Report) cnfHashes() []string {
+	hashes := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, gameResult := range report.Games {
+		if len(gameResult.CnfHash) > 0 && !seen[gameResult.CnfHash] {
+			seen[gameResult.CnfHash] = true
+			hashes = append(hashes, gameResult.CnfHash)
+		}
+	}
+
+	return hashes
+}
+
+// versions returns the hashes of the source of every AI in the games, which
+// identify the exact version that was evaluated
+func (report *Report) versions() [][2]string {
+	hashes := make(map[string][]string)
+
+	for _, gameResult := range report.Games {
+		for seat, hash := range gameResult.Hashes {
+			player := gameResult.Players[seat]
+			if !containsString(hashes[player], hash) {
+				hashes[player] = append(hashes[player], hash)
+			}
+		}
+	}
+
+	players := make([]string, 0, len(hashes))
+	for player := range hashes {
+		players = append(players, player)
+	}
+	sort.Strings(players)
+
+	versions := make([][2]string, len(players))
+	for i, player := range players {
+		versions[i] = [2]string{player, strings.Join(hashes[player], " ")}
+	}
+
+	return versions
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func scoresData(scores []int) []float64 {
+	data := make([]float64, len(scores))
+	for i, score := range scores {
+		data[i] = float64(score)
+	}
+
+	return data
+}
+
+// ranking returns the same ranking shown by dojo evaluate
+func (report *Report) ranking() reportTable {
+	table := reportTable{Header: []string{"#", "AI", "Rating", "1st%", "<=2nd%", "<=3rd%", "EvWin%", "Mean score", "Score stdev%", "95%", "99%", "CPU%", "MaxCPU%", "Games"}}
+
+	for i, evaluation := range report.Evaluation.Ranking {
+		numGames := len(evaluation.Scores)
+		data := scoresData(evaluation.Scores)
+
+		mean, _ := stats.Mean(data)
+		stdev, _ := stats.StandardDeviation(data)
+		percentile95, _ := stats.Percentile(data, 95)
+		percentile99, _ := stats.Percentile(data, 99)
+
+		winPercentageEvaluated := "-"
+		if evaluation.Player != report.Session.Player {
+			winPercentageEvaluated = ff(100 * float64(evaluation.NumWinsEvaluated) / float64(numGames))
+		}
+
+		cpu, maxCPU := "-", "-"
+		if len(evaluation.CPUs) > 0 {
+			avgCPU, _ := stats.Mean(evaluation.CPUs)
+			maxCPUValue, _ := stats.Max(evaluation.CPUs)
+			cpu, maxCPU = ff(100*avgCPU), ff(100*maxCPUValue)
+		}
+
+		table.Rows = append(table.Rows, []string{
+			strconv.Itoa(i + 1),
+			evaluation.Player,
+			ratingString(evaluation.Rating, evaluation.RatingDeviation, evaluation.RatingInterval),
+			ff(100 * float64(evaluation.NumGamesAtPlaceOrBetter[0]) / float64(numGames)),
+			ff(100 * float64(evaluation.NumGamesAtPlaceOrBetter[1]) / float64(numGames)),
+			ff(100 * float64(evaluation.NumGamesAtPlaceOrBetter[2]) / float64(numGames)),
+			winPercentageEvaluated,
+			ff(mean),
+			ff(100 * stdev / mean),
+			ff(percentile95),
+			ff(percentile99),
+			cpu,
+			maxCPU,
+			strconv.Itoa(numGames),
+		})
+	}
+
+	return table
+}
+
+// scoreRange returns the lowest and highest score of all the AIs
+func (report *Report) scoreRange() (int, int) {
+	low, high := math.MaxInt32, math.MinInt32
+
+	for _, evaluation := range report.Evaluation.Ranking {
+		for _, score := range evaluation.Scores {
+			if score < low {
+				low = score
+			}
+			if score > high {
+				high = score
+			}
+		}
+	}
+
+	if low > high {
+		return 0, 0
+	}
+
+	return low, high
+}
+
+// histogramBins returns the lowest score, the width and the number of the bins
+// that split the range of scores of all the AIs, at most numHistogramBins
+func (report *Report) histogramBins() (int, int, int) {
+	low, high := report.scoreRange()
+	width := (high - low + numHistogramBins) / numHistogramBins
+
+	return low, width, (high-low)/width + 1
+}
+
+// histogram returns the number of scores of an AI in each bin
+func (report *Report) histogram(scores []int) []int {
+	low, width, numBins := report.histogramBins()
+	bins := make([]int, numBins)
+
+	for _, score := range scores {
+		bins[(score-low)/width]++
+	}
+
+	return bins
+}
+
+// histogramBinLabels returns the range of scores of every bin of the histograms
+func (report *Report) histogramBinLabels() []string {
+	low, width, numBins := report.histogramBins()
+	labels := make([]string, numBins)
+
+	for i := range labels {
+		from := low + i*width
+		if width == 1 {
+			labels[i] = strconv.Itoa(from)
+		} else {
+			labels[i] = fmt.Sprintf("%d..%d", from, from+width-1)
+		}
+	}
+
+	return labels
+}
+
+// distribution returns statistics of the scores of every AI
+func (report *Report) distribution() reportTable {
+	table := reportTable{Header: []string{"AI", "Min", "25%", "Median", "75%", "Max", "Mean", "Stdev"}}
+
+	for _, evaluation := range report.Evaluation.Ranking {
+		data := scoresData(evaluation.Scores)
+
+		min, _ := stats.Min(data)
+		quartiles, _ := stats.Quartile(data)
+		max, _ := stats.Max(data)
+		mean, _ := stats.Mean(data)
+		stdev, _ := stats.StandardDeviation(data)
+
+		table.Rows = append(table.Rows, []string{
+			evaluation.Player, ff(min), ff(quartiles.Q1), ff(quartiles.Q2), ff(quartiles.Q3), ff(max), ff(mean), ff(stdev),
+		})
+	}
+
+	return table
+}
+
+// headToHead returns the head to head matrix, with the percentage of the
+// shared games where the AI of each row finished ahead of the AI of each
+// column and the number of games they shared
+func (report *Report) headToHead() reportTable {
+	table := reportTable{Header: []string{""}}
+	for _, opponent := range report.Evaluation.Ranking {
+		table.Header = append(table.Header, opponent.Player)
+	}
+
+	for _, evaluation := range report.Evaluation.Ranking {
+		row := []string{evaluation.Player}
+
+		for _, opponent := range report.Evaluation.Ranking {
+			if headToHead, ok := evaluation.HeadToHead[opponent.Player]; ok {
+				row = append(row, fmt.Sprintf("%s (%d)", ff(headToHead.WinPercentage()), headToHead.NumGames))
+			} else {
+				row = append(row, "-")
+			}
+		}
+
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table
+}
+
+// gamesTable returns the seeds, players and scores of every game
+func (report *Report) gamesTable() reportTable {
+	table := reportTable{Header: []string{"Game", "Seed", "Players", "Scores", "Outcome"}}
+
+	for _, gameResult := range report.Games {
+		scores := make([]string, len(gameResult.Scores))
+		for i, score := range gameResult.Scores {
+			scores[i] = strconv.Itoa(score)
+		}
+
+		outcome := string(gameResult.Outcome)
+		if len(gameResult.FailedPlayer) > 0 {
+			outcome += " (" + gameResult.FailedPlayer + ")"
+		}
+
+		table.Rows = append(table.Rows, []string{
+			gameResult.ID, gameResult.Seed, strings.Join(gameResult.Players, " "), strings.Join(scores, " "), outcome,
+		})
+	}
+
+	return table
+}
+
+func (report *Report) title() string {
+	return fmt.Sprintf("Evaluation of %s", report.Session.Player)
+}
+
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", "\\|", "<", "&lt;").Replace(s)
+}
+
+func writeMarkdownTable(b *strings.Builder, table reportTable) {
+	b.WriteString("|")
+	for _, column := range table.Header {
+		b.WriteString(" " + markdownEscape(column) + " |")
+	}
+	b.WriteString("\n|")
+	for range table.Header {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+
+	for _, row := range table.Rows {
+		b.WriteString("|")
+		for _, cell := range row {
+			b.WriteString(" " + markdownEscape(cell) + " |")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+}
+
+// sparkline draws the histogram of the scores of an AI with block characters
+func sparkline(bins []int) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+
+	max := 0
+	for _, count := range bins {
+		if count > max {
+			max = count
+		}
+	}
+
+	var b strings.Builder
+	for _, count := range bins {
+		if count == 0 {
+			b.WriteRune(' ')
+		} else {
+			b.WriteRune(blocks[(count*len(blocks)-1)/max])
+		}
+	}
+
+	return b.String()
+}
+
+func (report *Report) writeMarkdown(file *os.File) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", report.title())
+
+	b.WriteString("## Session\n\n")
+	metadata := reportTable{Header: []string{"Property", "Value"}}
+	for _, property := range report.metadata() {
+		metadata.Rows = append(metadata.Rows, []string{property[0], "`" + property[1] + "`"})
+	}
+	writeMarkdownTable(&b, metadata)
+
+	b.WriteString("## Ranking\n\n")
+	writeMarkdownTable(&b, report.ranking())
+
+	b.WriteString("## Score distribution\n\n")
+	distribution := report.distribution()
+	distribution.Header = append(distribution.Header, "Histogram")
+	for i, evaluation := range report.Evaluation.Ranking {
+		histogram := "`" + sparkline(report.histogram(evaluation.Scores)) + "`"
+		distribution.Rows[i] = append(distribution.Rows[i], histogram)
+	}
+	writeMarkdownTable(&b, distribution)
+
+	low, width, numBins := report.histogramBins()
+	fmt.Fprintf(&b, "The histograms split the scores from %d to %d in %d bins of %d.\n\n", low, low+numBins*width-1, numBins, width)
+
+	b.WriteString("## Head to head\n\n")
+	b.WriteString("Percentage of the shared games where the AI of the row finished ahead of the AI of the column, and number of shared games.\n\n")
+	writeMarkdownTable(&b, report.headToHead())
+
+	b.WriteString("## Games\n\n")
+	writeMarkdownTable(&b, report.gamesTable())
+
+	_, err := file.WriteString(b.String())
+
+	return err
+}
+
+// writeCSV writes every table of the report one after the other, separated
+// by an empty line and preceded by a row with the name of the table
+func (report *Report) writeCSV(file *os.File) error {
+	writer := csv.NewWriter(file)
+
+	writeTable := func(name string, table reportTable) {
+		writer.Write([]string{name})
+		writer.Write(table.Header)
+		writer.WriteAll(table.Rows)
+		writer.Write([]string{})
+	}
+
+	metadata := reportTable{Header: []string{"Property", "Value"}}
+	for _, property := range report.metadata() {
+		metadata.Rows = append(metadata.Rows, []string{property[0], property[1]})
+	}
+	writeTable("Session", metadata)
+
+	writeTable("Ranking", report.ranking())
+
+	distribution := report.distribution()
+	distribution.Header = append(distribution.Header, report.histogramBinLabels()...)
+	for i, evaluation := range report.Evaluation.Ranking {
+		for _, count := range report.histogram(evaluation.Scores) {
+			distribution.Rows[i] = append(distribution.Rows[i], strconv.Itoa(count))
+		}
+	}
+	writeTable("Score distribution", distribution)
+
+	// The head to head data is written in long form, which is easier to
+	// analyze than the matrix
+	headToHead := reportTable{Header: []string{"AI", "Opponent", "Games", "Ahead", "Tied", "Win%"}}
+	for _, evaluation := range report.Evaluation.Ranking {
+		for _, opponent := range report.Evaluation.Ranking {
+			if h, ok := evaluation.HeadToHead[opponent.Player]; ok {
+				headToHead.Rows = append(headToHead.Rows, []string{
+					evaluation.Player, opponent.Player, strconv.Itoa(h.NumGames), strconv.Itoa(h.NumAhead), strconv.Itoa(h.NumTied), ff(h.WinPercentage()),
+				})
+			}
+		}
+	}
+	writeTable("Head to head", headToHead)
+
+	writeTable("Games", report.gamesTable())
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// htmlHistogram is the score histogram of an AI in the HTML report
+type htmlHistogram struct {
+	Player string
+	Bins   []htmlBin
+}
+
+type htmlBin struct {
+	Label  string
+	Count  int
+	Height float64
+}
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 1200px; color: #222; }
+h1 { margin-bottom: 0.2em; }
+table { border-collapse: collapse; margin: 1em 0; font-size: 14px; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: right; }
+th { background: #f3f3f3; }
+td:first-child, th:first-child { text-align: left; }
+tr.evaluated td { font-weight: bold; background: #fffbe6; }
+.metadata td { text-align: left; font-family: monospace; }
+.histograms { display: flex; flex-wrap: wrap; gap: 2em; }
+.histogram { display: flex; align-items: flex-end; height: 80px; gap: 2px; border-bottom: 1px solid #999; }
+.histogram div { width: 14px; background: #4c8bf5; }
+.note { color: #666; font-size: 13px; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="note">Session {{.Session}}, generated by dojo {{.Version}}</p>
+
+<h2>Ranking</h2>
+<table>
+<tr>{{range .Ranking.Header}}<th>{{.}}</th>{{end}}</tr>
+{{range $i, $row := .Ranking.Rows}}<tr{{if eq (index $row 1) $.Player}} class="evaluated"{{end}}>{{range $row}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+
+<h2>Score distribution</h2>
+<table>
+<tr>{{range .Distribution.Header}}<th>{{.}}</th>{{end}}</tr>
+{{range .Distribution.Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+<div class="histograms">
+{{range .Histograms}}<div>
+<div class="histogram">{{range .Bins}}<div style="height: {{.Height}}%" title="{{.Label}}: {{.Count}} games"></div>{{end}}</div>
+<p>{{.Player}}</p>
+</div>
+{{end}}</div>
+<p class="note">Scores from {{.ScoreRange}} split in {{.NumBins}} bins, hover a bar to see its range and count.</p>
+
+<h2>Head to head</h2>
+<p class="note">Percentage of the shared games where the AI of the row finished ahead of the AI of the column, and number of shared games.</p>
+<table>
+<tr>{{range .HeadToHead.Header}}<th>{{.}}</th>{{end}}</tr>
+{{range .HeadToHead.Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+
+<h2>Session</h2>
+<table class="metadata">
+{{range .Metadata}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{end}}</table>
+
+<h2>Games</h2>
+<table>
+<tr>{{range .Games.Header}}<th>{{.}}</th>{{end}}</tr>
+{{range .Games.Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+</body>
+</html>
+`
+
+func (report *Report) writeHTML(file *os.File) error {
+	tmpl, err := template.New("report").Parse(htmlReportTemplate)
+	if err != nil {
+		return err
+	}
+
+	labels := report.histogramBinLabels()
+	histograms := make([]htmlHistogram, 0, len(report.Evaluation.Ranking))
+
+	for _, evaluation := range report.Evaluation.Ranking {
+		counts := report.histogram(evaluation.Scores)
+
+		max := 1
+		for _, count := range counts {
+			if count > max {
+				max = count
+			}
+		}
+
+		histogram := htmlHistogram{Player: evaluation.Player}
+		for i, count := range counts {
+			histogram.Bins = append(histogram.Bins, htmlBin{labels[i], count, math.Round(1000*float64(count)/float64(max)) / 10})
+		}
+
+		histograms = append(histograms, histogram)
+	}
+
+	low, width, numBins := report.histogramBins()
+
+	return tmpl.Execute(file, map[string]interface{}{
+		"Title":        report.title(),
+		"Session":      report.Session.ID,
+		"Player":       report.Session.Player,
+		"Version":      version,
+		"Ranking":      report.ranking(),
+		"Distribution": report.distribution(),
+		"Histograms":   histograms,
+		"ScoreRange":   fmt.Sprintf("%d to %d", low, low+numBins*width-1),
+		"NumBins":      numBins,
+		"HeadToHead":   report.headToHead(),
+		"Metadata":     report.metadata(),
+		"Games":        report.gamesTable(),
+	})
+}
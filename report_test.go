@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// reportFixture records an evaluation session of Dojo with two finished games
+// and a crashed one in the current directory
+func reportFixture(t *testing.T) {
+	games := []GameResult{
+		{ID: "game-0", Seed: "1", Players: []string{"Dojo", "Dummy", "Null", "Dummy"}, PlayersSorted: []string{"Dojo", "Dummy", "Dummy", "Null"},
+			Scores: []int{90, 40, 10, 30}, Winner: 0, Outcome: OutcomeOk},
+		{ID: "game-1", Seed: "2", Players: []string{"Dummy", "Dojo", "Dummy", "Null"}, PlayersSorted: []string{"Dojo", "Dummy", "Dummy", "Null"},
+			Scores: []int{50, 80, 20, 5}, Winner: 1, Outcome: OutcomeOk},
+		{ID: "game-2", Seed: "3", Players: []string{"Null", "Dummy", "Dojo", "Dummy"}, Outcome: OutcomeCrash, FailedPlayer: "Null"},
+	}
+
+	session := &Session{
+		ID:      "20261019-120000.000-0001",
+		Time:    time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+		Ai:      "Dojo:0",
+		Player:  "Dojo",
+		Options: EvaluateOptions{NumGames: 3, Against: []string{"Dummy", "Null"}, SelectionSeed: 7, MaxFailures: -1},
+		Games:   make(map[int]string),
+		Status:  SessionFinished,
+	}
+
+	for i, gameResult := range games {
+		if err := saveGameResult(gameResult); err != nil {
+			t.Fatal(err)
+		}
+
+		session.Games[i] = gameResult.ID
+		session.Order = append(session.Order, i)
+	}
+
+	if err := session.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dojo-report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	reportFixture(t)
+
+	report, err := loadReport("last")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		FileName string
+		Expected []string
+	}{
+		{"report.html", []string{
+			"<title>Evaluation of Dojo</title>",
+			"<th>&lt;=2nd%</th>",
+			`<tr class="evaluated"><td>1</td><td>Dojo</td><td>`,
+			`title="77..85: 1 games"`,
+			"<tr><td>Dojo</td><td>-</td><td>100.00 (2)</td><td>100.00 (2)</td></tr>",
+			"<tr><th>Failed games</th><td>1</td></tr>",
+			"<tr><td>game-2</td><td>3</td><td>Null Dummy Dojo Dummy</td><td></td><td>crash (Null)</td></tr>",
+		}},
+		{"report.md", []string{
+			"# Evaluation of Dojo\n",
+			"| # | AI | Rating | 1st% | &lt;=2nd% |",
+			"| 1 | Dojo | ",
+			"| 100.00 | 100.00 | 100.00 | - | 85.00 |",
+			"| Failed games | `1` |",
+			"| Dojo | - | 100.00 (2) | 100.00 (2) |",
+			"| game-2 | 3 | Null Dummy Dojo Dummy |  | crash (Null) |",
+		}},
+		{"report.csv", []string{
+			"Session\nProperty,Value\nSession,20261019-120000.000-0001\n",
+			`Limits,"timeout 0s, memory 0 MB, CPU 0 s"`,
+			"\n1,Dojo,",
+			"Dojo,80.00,80.00,85.00,90.00,90.00,85.00,5.00,0,0,0,0,0,0,0,0,1,1\n",
+			"Head to head\nAI,Opponent,Games,Ahead,Tied,Win%\nDojo,Dummy,2,2,0,100.00\n",
+			"game-2,3,Null Dummy Dojo Dummy,,crash (Null)\n",
+		}},
+	}
+
+	for _, test := range tests {
+		if err := report.Write(test.FileName); err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := ioutil.ReadFile(test.FileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, expected := range test.Expected {
+			if !strings.Contains(string(content), expected) {
+				t.Errorf("Found report %s\n%s\nexpected it to contain %q", test.FileName, content, expected)
+			}
+		}
+	}
+
+	// Every table of the CSV report has rows of the same length as its header
+	file, _ := os.Open("report.csv")
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Tables start with a record with their name followed by the header
+	var header []string
+	for _, record := range records {
+		if len(record) == 1 {
+			header = nil
+		} else if header == nil {
+			header = record
+		} else if len(record) != len(header) {
+			t.Error("Found row", record, "with", len(record), "fields, expected", len(header))
+		}
+	}
+
+	if err := report.Write("report.txt"); err == nil {
+		t.Error("Found no error writing a report with an unknown format")
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/albertsgrc/dojo/v2/ai"
+)
+
+// rerunDescriptors returns the descriptors that reproduce exactly the seats of
+// a recorded game, after applying the substitutions, each with the format
+// <player name or seat number>=<AI descriptor>
+func rerunDescriptors(gameResult GameResult, substitutions []string) ([]string, error) {
+	descriptors := make([]string, len(gameResult.Players))
+
+	for i, player := range gameResult.Players {
+		descriptors[i] = ai.PlayerDescriptor(player)
+	}
+
+	for _, substitution := range substitutions {
+		split := strings.SplitN(substitution, "=", 2)
+
+		if len(split) != 2 {
+			return nil, fmt.Errorf("invalid substitution '%s', expected <player>=<descriptor>", substitution)
+		}
+
+		seat, err := strconv.Atoi(split[0])
+
+		if err != nil {
+			seat = -1
+			for i, player := range gameResult.Players {
+				if player == split[0] {
+					seat = i
+					break
+				}
+			}
+		}
+
+		if seat < 0 || seat >= len(descriptors) {
+			return nil, fmt.Errorf("player '%s' did not play game %s", split[0], gameResult.ID)
+		}
+
+		descriptors[seat] = split[1]
+	}
+
+	return descriptors, nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRerunDescriptors(t *testing.T) {
+	gameResult := GameResult{ID: "game", Players: []string{"Dojo_3", "Dummy", "Dojo_v2_1", "Null_Bot"}}
+
+	tests := []struct {
+		Substitutions []string
+		Expected      []string
+		Valid         bool
+	}{
+		{nil, []string{"Dojo:3", "Dummy:0", "Dojo_v2:1", "Null_Bot:0"}, true},
+		{[]string{"Dojo_v2_1=Dojo_v2:2"}, []string{"Dojo:3", "Dummy:0", "Dojo_v2:2", "Null_Bot:0"}, true},
+		{[]string{"Null_Bot=Dummy", "0=Dojo:-1"}, []string{"Dojo:-1", "Dummy:0", "Dojo_v2:1", "Dummy"}, true},
+		{[]string{"Dojo_v2=Dummy"}, nil, false},
+		{[]string{"4=Dummy"}, nil, false},
+		{[]string{"Dummy"}, nil, false},
+	}
+
+	for _, test := range tests {
+		descriptors, err := rerunDescriptors(gameResult, test.Substitutions)
+
+		if (err == nil) != test.Valid {
+			t.Error("Found error", err, "with the substitutions", test.Substitutions, ", expected valid", test.Valid)
+		} else if test.Valid && !reflect.DeepEqual(descriptors, test.Expected) {
+			t.Error("Found", descriptors, "with the substitutions", test.Substitutions, ", expected", test.Expected)
+		}
+	}
+}
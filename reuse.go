@@ -0,0 +1,78 @@
+package main
+
+import (
+	"github.com/albertsgrc/dojo/v2/ai"
+)
+
+// reusableGames returns the recorded games that can be taken into account in
+// an evaluation of the pool of AIs, which are the ones that finished correctly
+// and were played only by AIs of the pool, with the same source they have now
+// and the same game executable and configuration. Only the games played by
+// origin are considered, or the ones played by any command if it is empty. It
+// also returns the number of games that were played by AIs of the pool but
+// are outdated, games recorded without hashes are ignored.
+func reusableGames(pool []*ai.Ai, cnfFile string, origin string) ([]GameResult, int, error) {
+	cnfHash, err := fileHash(cnfFile)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	gameHash, err := executableHash(defaultGame)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hashes := make(map[string]string)
+	for _, player := range pool {
+		hash, err := fileHash(player.FileName)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		hashes[player.PlayerName()] = hash
+	}
+
+	gameResults, err := loadGameResults()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	reusable := make([]GameResult, 0)
+	numOutdated := 0
+
+	for _, gameResult := range gameResults {
+		// Games recorded without hashes cannot be checked
+		if gameResult.Failed() || len(gameResult.Players) != 4 || len(gameResult.Hashes) != len(gameResult.Players) || len(gameResult.GameHash) == 0 {
+			continue
+		}
+
+		// The games of check-determinism are played twice on purpose
+		if gameResult.Origin == "check-determinism" || (len(origin) > 0 && gameResult.Origin != origin) {
+			continue
+		}
+
+		inPool, upToDate := true, gameResult.CnfHash == cnfHash && gameResult.GameHash == gameHash
+
+		for i, player := range gameResult.Players {
+			hash, ok := hashes[player]
+
+			if !ok {
+				inPool = false
+			} else if upToDate && gameResult.Hashes[i] != hash {
+				upToDate = false
+			}
+		}
+
+		if !inPool {
+			continue
+		}
+
+		if upToDate {
+			reusable = append(reusable, gameResult)
+		} else {
+			numOutdated++
+		}
+	}
+
+	return reusable, numOutdated, nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/albertsgrc/dojo/v2/ai"
+)
+
+func TestReusableGames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dojo-reuse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir)
+	defer os.Setenv("PATH", path)
+
+	if err := ioutil.WriteFile(defaultGame, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(defaultCnf, []byte("nb_rounds 200\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pool := make([]*ai.Ai, 0)
+	for _, name := range []string{"Dojo", "Dummy", "Null", "Foo"} {
+		fileName := filepath.Join(dir, "AI"+name+".cc")
+		if err := ioutil.WriteFile(fileName, []byte("// "+name+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		pool = append(pool, &ai.Ai{Name: name, FileName: fileName})
+	}
+
+	players := []string{"Dojo", "Dummy", "Null", "Foo"}
+	hashes := make([]string, len(pool))
+	for i, player := range pool {
+		hashes[i], _ = fileHash(player.FileName)
+	}
+	cnfHash, _ := fileHash(defaultCnf)
+	gameHash, _ := executableHash(defaultGame)
+
+	game := func(id string, edit func(*GameResult)) GameResult {
+		gameResult := GameResult{
+			ID:       id,
+			Players:  players,
+			Scores:   []int{4, 3, 2, 1},
+			CnfHash:  cnfHash,
+			Hashes:   append([]string{}, hashes...),
+			GameHash: gameHash,
+			Origin:   "evaluate",
+			Outcome:  OutcomeOk,
+		}
+		edit(&gameResult)
+
+		return gameResult
+	}
+
+	gameResults := []GameResult{
+		game("ok", func(*GameResult) {}),
+		game("other-player-hash", func(gr *GameResult) { gr.Hashes[2] = "changed" }),
+		game("other-game-hash", func(gr *GameResult) { gr.GameHash = "changed" }),
+		game("other-cnf-hash", func(gr *GameResult) { gr.CnfHash = "changed" }),
+		game("not-in-pool", func(gr *GameResult) { gr.Players = []string{"Dojo", "Dummy", "Null", "Bar"} }),
+		game("failed", func(gr *GameResult) { gr.Outcome = OutcomeCrash }),
+		game("without-hashes", func(gr *GameResult) { gr.Hashes = nil }),
+		game("check-determinism", func(gr *GameResult) { gr.Origin = "check-determinism" }),
+		game("run", func(gr *GameResult) { gr.Origin = "run" }),
+	}
+
+	for _, gameResult := range gameResults {
+		if err := saveGameResult(gameResult); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := func(gameResults []GameResult) []string {
+		ids := make([]string, len(gameResults))
+		for i, gameResult := range gameResults {
+			ids[i] = gameResult.ID
+		}
+
+		return ids
+	}
+
+	tests := []struct {
+		Origin      string
+		Expected    []string
+		NumOutdated int
+	}{
+		{"evaluate", []string{"ok"}, 3},
+		{"", []string{"ok", "run"}, 3},
+		{"match", []string{}, 0},
+	}
+
+	for _, test := range tests {
+		reusable, numOutdated, err := reusableGames(pool, defaultCnf, test.Origin)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if found := ids(reusable); !reflect.DeepEqual(found, test.Expected) || numOutdated != test.NumOutdated {
+			t.Error("Found", found, "reusable and", numOutdated, "outdated with the origin", test.Origin, ", expected", test.Expected, "and", test.NumOutdated)
+		}
+	}
+
+	// Changing the source of a player outdates its games
+	if err := ioutil.WriteFile(pool[0].FileName, []byte("// Dojo v2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if reusable, numOutdated, _ := reusableGames(pool, defaultCnf, "evaluate"); len(reusable) != 0 || numOutdated != 4 {
+		t.Error("Found", ids(reusable), "reusable and", numOutdated, "outdated after changing the source, expected none and 4")
+	}
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
@@ -14,17 +17,82 @@ import (
 	"github.com/jedib0t/go-pretty/text"
 
 	"github.com/albertsgrc/dojo/v2/ai"
+	"github.com/albertsgrc/dojo/v2/replay"
 )
 
 // GameResult ...
 type GameResult struct {
+	ID   string
+	Time time.Time
+	// Descriptors are the player descriptors the game was requested with
+	Descriptors []string
+	// Players contains the resolved player of each seat
 	Players       []string
 	PlayersSorted []string
 	Scores        []int
 	Winner        int
+	Seed          string
+	// SelectionSeed is the seed used to pick the player versions and seats
+	SelectionSeed int64
+	// CnfOverrides contains the values of default.cnf that were overridden
+	CnfOverrides []string
+	// CnfHash is the hash of the game configuration file
+	CnfHash string
+	// Hashes contains the hash of the source of the player of each seat
+	Hashes []string
+	// GameHash is the hash of the game executable
+	GameHash string
+	// Origin is the command that played the game, such as evaluate or run
+	Origin  string
+	Outcome Outcome
+	// FailedPlayer is the player whose turn was in progress when the game
+	// failed, empty if it could not be determined from the output
+	FailedPlayer string
+	// Error describes the failure of the game
+	Error string
+	// Timeline contains the score of each player over the rounds, indexed
+	// first by player (in the same order as Players) and then by round
+	Timeline [][]int
+	// CPU contains the fraction of its time budget that each player used, or
+	// -1 if the player was disqualified for exceeding it
+	CPU []float64
+	// Backtrace is the backtrace of the crash when the game was debugged
+	Backtrace string
 }
 
-type ByScoreDescending GameResult
+// RunOptions ...
+type RunOptions struct {
+	Seed string
+	// SelectionSeed is the seed used to pick the player versions among the
+	// ones matching each descriptor and to shuffle the seats
+	SelectionSeed int64
+	Shuffle       bool
+	PrintOutput   bool
+	Limits        utils.Limits
+	// ResFile is the file where the game result is written, a temporary file
+	// that is removed after the game when empty
+	ResFile string
+	// KeepReplay copies the game result to the game record so that it can be viewed later
+	KeepReplay bool
+	// KeepLogs stores the lines written by each player in the game record
+	KeepLogs bool
+	// Game is the game executable, Game by default
+	Game string
+	// Origin is the command that plays the game, recorded with its result
+	Origin string
+	// Env contains environment variables for the game
+	Env []string
+	// Cnf is the game configuration, default.cnf when nil
+	Cnf *Cnf
+	// OnRound is called when the game starts a new round
+	OnRound func(round int)
+}
+
+// ByScoreDescending sorts players by their score, from highest to lowest
+type ByScoreDescending struct {
+	Players []string
+	Scores  []int
+}
 
 func (a ByScoreDescending) Len() int {
 	return len(a.Players)
@@ -35,10 +103,15 @@ func (a ByScoreDescending) Less(i, j int) bool {
 }
 
 func (a ByScoreDescending) Swap(i, j int) {
-	a.PlayersSorted[i], a.PlayersSorted[j] = a.PlayersSorted[j], a.PlayersSorted[i]
+	a.Players[i], a.Players[j] = a.Players[j], a.Players[i]
+	a.Scores[i], a.Scores[j] = a.Scores[j], a.Scores[i]
 }
 
 func (gr GameResult) String() string {
+	if gr.Failed() {
+		return gr.failureString()
+	}
+
 	s := ""
 
 	for i := 0; i < 4; i++ {
@@ -64,28 +137,41 @@ func (gr GameResult) String() string {
 			prefix = "✌️  "
 		}
 
+		cpu := ""
+		if i < len(gr.CPU) {
+			cpu = "   " + cpuString(gr.CPU[i])
+		}
+
 		s += fmt.Sprintln(
 			nameStyler.Sprint(prefix, text.AlignLeft.Apply(gr.Players[i], 14),
-				valueStyler.Sprint(score)))
+				valueStyler.Sprint(text.AlignLeft.Apply(strconv.Itoa(score), 6)), cpu))
 	}
 
 	return s
 }
 
-func parseGameResult(output string) GameResult {
+func parseGameResult(output string) (GameResult, error) {
 	lines := strings.Split(output, "\n")
+	if len(lines) < 7 {
+		return GameResult{}, fmt.Errorf("game output is too short")
+	}
 	lines = lines[len(lines)-7 : len(lines)-3]
 
 	r, _ := regexp.Compile(`player ([_\d\w]+) got score (\d+)`)
 	gameResult := GameResult{
 		Players: make([]string, 4),
 		Scores:  make([]int, 4),
+		Outcome: OutcomeOk,
 	}
 
 	maxScore := 0
 	for i, line := range lines {
 		res := r.FindAllStringSubmatch(line, 2)
 
+		if len(res) == 0 {
+			return GameResult{}, fmt.Errorf("no score found in line '%s'", line)
+		}
+
 		gameResult.Players[i] = res[0][1]
 		gameResult.Scores[i], _ = strconv.Atoi(res[0][2])
 
@@ -98,21 +184,83 @@ func parseGameResult(output string) GameResult {
 	gameResult.PlayersSorted = make([]string, 4)
 	copy(gameResult.PlayersSorted, gameResult.Players)
 
-	sort.Sort(ByScoreDescending(gameResult))
+	sort.Stable(ByScoreDescending{gameResult.PlayersSorted, append([]int{}, gameResult.Scores...)})
+
+	return gameResult, nil
+}
+
+// defaultGame is the game executable, found in the PATH
+const defaultGame = "Game"
+
+// maxGameSeed is the upper bound (exclusive) of the game seeds generated by dojo
+const maxGameSeed = 2147479307
+
+func timeSeed() int64 {
+	return time.Now().UnixNano() / 1000
+}
+
+// parseSeed parses a seed that is either a number or the string 'time'
+func parseSeed(seed string) (int64, error) {
+	if seed == "time" {
+		return timeSeed(), nil
+	}
+
+	value, err := strconv.ParseInt(seed, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid seed '%s', expected a number or 'time'", seed)
+	}
+
+	return value, nil
+}
+
+var startRoundRegexp = regexp.MustCompile(`start round (\d+)`)
+
+// runningPlayer returns the index of the player whose turn was started but not
+// finished in the game output, or -1 if there is none. Only the markers of the
+// game count, not the lines of the players that look like them
+func runningPlayer(output string) int {
+	player := -1
+
+	for _, line := range strings.Split(output, "\n") {
+		if match := playerMarkerRegexp.FindStringSubmatch(line); match != nil {
+			player = -1
+			if match[1] == "start" {
+				player, _ = strconv.Atoi(match[2])
+			}
+		}
+	}
+
+	return player
+}
+
+// executableHash returns the hash of an executable, which is looked up in the
+// PATH if its name has no slashes
+func executableHash(name string) (string, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", err
+	}
+
+	return fileHash(path)
+}
 
-	return gameResult
+func gameArgs(players []string, seed string, cnfFile string, resFile string) []string {
+	return append(append([]string{}, players...), "-s", seed, "-i", cnfFile, "-o", resFile)
 }
 
 // Run ...
-func Run(playerDescriptors []string, seed string, shuffle bool, printOutput bool) (GameResult, error) {
+func Run(playerDescriptors []string, options RunOptions) (GameResult, error) {
 	if len(playerDescriptors) != 4 {
 		return GameResult{}, fmt.Errorf("Invalid number of players '%d'", len(playerDescriptors))
 	}
 
-	s := rand.NewSource(time.Now().UnixNano() / 1000)
-	randGenTime := rand.New(s)
+	id := newGameID()
+
+	s := rand.NewSource(options.SelectionSeed)
+	randGenSelection := rand.New(s)
 
 	players := make([]string, 4)
+	hashes := make([]string, 4)
 
 	for i, player := range playerDescriptors {
 		ais := ai.List(ai.DescriptorFromString(player))
@@ -121,34 +269,139 @@ func Run(playerDescriptors []string, seed string, shuffle bool, printOutput bool
 			return GameResult{}, fmt.Errorf("No AIs found for player %d with descriptor %s", i, player)
 		}
 
-		ai := ais[randGenTime.Intn(len(ais))]
+		ai := ais[randGenSelection.Intn(len(ais))]
 
 		players[i] = ai.PlayerName()
+
+		hash, err := fileHash(ai.FileName)
+		if err != nil {
+			return GameResult{}, err
+		}
+
+		hashes[i] = hash
 	}
 
-	if shuffle {
-		randGenTime.Shuffle(len(players), func(i, j int) {
+	if options.Shuffle {
+		randGenSelection.Shuffle(len(players), func(i, j int) {
 			players[i], players[j] = players[j], players[i]
+			hashes[i], hashes[j] = hashes[j], hashes[i]
 		})
 	}
 
+	seed := options.Seed
 	if seed == "time" {
-		seed = strconv.FormatInt((time.Now().UnixNano()/1000)%2147479307, 10)
+		seed = strconv.FormatInt(timeSeed()%maxGameSeed, 10)
+	}
+
+	resFile := options.ResFile
+	if len(resFile) == 0 {
+		file, err := ioutil.TempFile("", "dojo-*.res")
+		if err != nil {
+			return GameResult{}, err
+		}
+		file.Close()
+
+		resFile = file.Name()
+		defer os.Remove(resFile)
 	}
 
-	_, stderr, err := utils.Exec("Game", printOutput,
-		players[0], players[1], players[2], players[3],
-		"-s", seed, "-i", "default.cnf", "-o", "default.res")
+	game := options.Game
+	if len(game) == 0 {
+		game = defaultGame
+	}
 
+	gameHash, err := executableHash(game)
 	if err != nil {
-		fmt.Println(stderr)
-		utils.Error("Running the game failed, see error above ^")
-		os.Exit(1)
+		return GameResult{}, err
+	}
+
+	cnf := options.Cnf
+	if cnf == nil {
+		cnf = &Cnf{File: defaultCnf}
+	}
 
+	cnfHash, err := fileHash(cnf.File)
+	if err != nil {
 		return GameResult{}, err
 	}
 
-	gameResult := parseGameResult(stderr)
+	startTime := time.Now()
+
+	execOptions := utils.ExecOptions{PrintOutput: options.PrintOutput, Limits: options.Limits, Env: options.Env}
+
+	if options.OnRound != nil {
+		execOptions.OnStderrLine = func(line string) {
+			if match := startRoundRegexp.FindStringSubmatch(line); match != nil {
+				round, _ := strconv.Atoi(match[1])
+				options.OnRound(round)
+			}
+		}
+	}
+	_, stderr, err := utils.Exec(game, execOptions, gameArgs(players, seed, cnf.File, resFile)...)
+
+	var gameResult GameResult
+
+	if err != nil {
+		if _, isExitError := err.(*exec.ExitError); !isExitError && err != utils.ErrTimeout && err != utils.ErrCPULimit {
+			return GameResult{}, err
+		}
+
+		gameResult = GameResult{Players: players, Outcome: OutcomeCrash, Error: tail(stderr, 20)}
+
+		if report := sanitizerReport(stderr); len(report) > 0 {
+			gameResult.Error = report
+		}
+
+		if player := runningPlayer(stderr); player >= 0 && player < len(players) {
+			gameResult.FailedPlayer = players[player]
+			gameResult.Outcome = OutcomePlayerAborted
+		}
+
+		if err == utils.ErrTimeout || err == utils.ErrCPULimit {
+			gameResult.Outcome = OutcomeTimeout
+		}
+	} else if gameResult, err = parseGameResult(stderr); err != nil {
+		gameResult = GameResult{Players: players, Outcome: OutcomeParseFailure, Error: err.Error()}
+	} else if gameReplay, err := replay.ParseFile(resFile); err == nil {
+		gameResult.Timeline = gameReplay.Timeline()
+		gameResult.CPU = gameReplay.CPU()
+	}
+
+	gameResult.ID = id
+	gameResult.Time = startTime
+	gameResult.Descriptors = playerDescriptors
+	gameResult.Seed = seed
+	gameResult.SelectionSeed = options.SelectionSeed
+	gameResult.CnfOverrides = cnf.Overrides
+	gameResult.CnfHash = cnfHash
+	gameResult.Hashes = hashes
+	gameResult.GameHash = gameHash
+	gameResult.Origin = options.Origin
+
+	if err := saveGameResult(gameResult); err != nil {
+		return gameResult, err
+	}
+
+	if options.KeepLogs {
+		if err := saveLogs(id, splitLogs(stderr, players)); err != nil {
+			return gameResult, err
+		}
+	}
+
+	if options.KeepReplay {
+		return gameResult, copyFile(resFile, filepath.Join(gameDir(id), replayFileName))
+	}
 
 	return gameResult, nil
 }
+
+// tail returns the last n lines of s
+func tail(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	return strings.Join(lines, "\n")
+}
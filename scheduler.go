@@ -0,0 +1,391 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/albertsgrc/dojo/v2/ai"
+)
+
+// lineupSize is the number of players of a game
+const lineupSize = 4
+
+// Scheduler picks the players of every game of an evaluation
+type Scheduler interface {
+	// Next returns the descriptors of the players of the next game
+	Next(randGen *rand.Rand) []string
+}
+
+// poolAi is an AI of a pool together with how likely it is to be picked
+// relative to the rest of the pool
+type poolAi struct {
+	ai     *ai.Ai
+	weight float64
+}
+
+// parsePoolEntry parses a descriptor optionally followed by a weight, e.g.
+// Dummy*3. The weight is 1 when missing
+func parsePoolEntry(entry string) (string, float64, error) {
+	split := strings.Split(entry, "*")
+
+	switch len(split) {
+	case 1:
+		return entry, 1, nil
+	case 2:
+		weight, err := strconv.ParseFloat(split[1], 64)
+		if err != nil || math.IsNaN(weight) || math.IsInf(weight, 0) || weight <= 0 {
+			return "", 0, fmt.Errorf("invalid weight '%s' in '%s', it must be a positive number", split[1], entry)
+		}
+
+		return split[0], weight, nil
+	default:
+		return "", 0, fmt.Errorf("invalid pool entry '%s', expected AI_DESCR or AI_DESCR*WEIGHT", entry)
+	}
+}
+
+// listPool returns the AIs matching the given pool entries with their
+// weights. An AI matched by several entries takes the weight of the last one
+func listPool(entries []string) ([]poolAi, error) {
+	pool := make([]poolAi, 0)
+	indices := make(map[string]int)
+
+	for _, entry := range entries {
+		descriptor, weight, err := parsePoolEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, matching := range ai.List(ai.DescriptorFromString(descriptor)) {
+			if i, ok := indices[matching.PlayerName()]; ok {
+				pool[i].weight = weight
+				continue
+			}
+
+			indices[matching.PlayerName()] = len(pool)
+			pool = append(pool, poolAi{matching, weight})
+		}
+	}
+
+	return pool, nil
+}
+
+// parsePools parses definitions of named pools such as
+// strong=Dojo:-2..,Dojo_1*2 into the entries of every pool
+func parsePools(definitions []string) (map[string][]string, error) {
+	pools := make(map[string][]string)
+
+	for _, definition := range definitions {
+		split := strings.SplitN(definition, "=", 2)
+		name := strings.TrimSpace(split[0])
+
+		if len(split) != 2 || len(name) == 0 || len(strings.TrimSpace(split[1])) == 0 {
+			return nil, fmt.Errorf("invalid pool '%s', expected NAME=AI_DESCR[*WEIGHT],...", definition)
+		}
+
+		if name == meSlot || name == againstSlot {
+			return nil, fmt.Errorf("the pool name '%s' is reserved", name)
+		}
+
+		for _, entry := range strings.Split(split[1], ",") {
+			pools[name] = append(pools[name], strings.TrimSpace(entry))
+		}
+	}
+
+	return pools, nil
+}
+
+// pick returns an AI of the pool picked at random in proportion to its
+// weight, avoiding the used ones unless all of them have been used
+func pick(randGen *rand.Rand, pool []poolAi, used map[string]bool) string {
+	candidates := make([]poolAi, 0, len(pool))
+	total := 0.0
+
+	for _, candidate := range pool {
+		if !used[candidate.ai.Descriptor()] {
+			candidates = append(candidates, candidate)
+			total += candidate.weight
+		}
+	}
+
+	if len(candidates) == 0 {
+		candidates = pool
+		for _, candidate := range pool {
+			total += candidate.weight
+		}
+	}
+
+	x := randGen.Float64() * total
+	for _, candidate := range candidates {
+		x -= candidate.weight
+		if x < 0 {
+			return candidate.ai.Descriptor()
+		}
+	}
+
+	return candidates[len(candidates)-1].ai.Descriptor()
+}
+
+// sample returns n AIs of the pool, all of them different if the pool is
+// large enough and repeating them otherwise
+func sample(randGen *rand.Rand, pool []poolAi, n int) []string {
+	descriptors := make([]string, 0, n)
+	used := make(map[string]bool)
+
+	for len(descriptors) < n {
+		descriptor := pick(randGen, pool, used)
+		used[descriptor] = true
+		descriptors = append(descriptors, descriptor)
+	}
+
+	return descriptors
+}
+
+// weighted returns whether any AI of the pool has a weight other than 1
+func weighted(pool []poolAi) bool {
+	for _, poolAi := range pool {
+		if poolAi.weight != 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// schedulers contains the constructor of every scheduler by its name
+var schedulers = map[string]func(evaluatedAi *ai.Ai, pool []poolAi) (Scheduler, error){
+	"random":      newRandomScheduler,
+	"gauntlet":    newGauntletScheduler,
+	"round-robin": newRoundRobinScheduler,
+	"balanced":    newBalancedScheduler,
+}
+
+// defaultScheduler is the scheduler used when none is chosen
+const defaultScheduler = "random"
+
+// newScheduler returns the scheduler with the given name for a pool of AIs
+// that includes the evaluated one
+func newScheduler(name string, evaluatedAi *ai.Ai, pool []poolAi) (Scheduler, error) {
+	newScheduler, ok := schedulers[name]
+
+	if !ok {
+		names := make([]string, 0, len(schedulers))
+		for name := range schedulers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		return nil, fmt.Errorf("unknown scheduler '%s', use %s", name, strings.Join(names, ", "))
+	}
+
+	return newScheduler(evaluatedAi, pool)
+}
+
+// randomScheduler picks the players of every game at random from the pool
+type randomScheduler struct {
+	pool []poolAi
+}
+
+func newRandomScheduler(evaluatedAi *ai.Ai, pool []poolAi) (Scheduler, error) {
+	if len(pool) < 2 {
+		return nil, fmt.Errorf("the random scheduler needs at least 2 AIs in the pool, found %d", len(pool))
+	}
+
+	return &randomScheduler{pool}, nil
+}
+
+func (scheduler *randomScheduler) Next(randGen *rand.Rand) []string {
+	return sample(randGen, scheduler.pool, lineupSize)
+}
+
+// gauntletScheduler plays the evaluated AI in every game, against opponents
+// picked at random from the rest of the pool
+type gauntletScheduler struct {
+	evaluatedAi *ai.Ai
+	opponents   []poolAi
+}
+
+func newGauntletScheduler(evaluatedAi *ai.Ai, pool []poolAi) (Scheduler, error) {
+	opponents := make([]poolAi, 0, len(pool))
+	for _, opponent := range pool {
+		if opponent.ai.PlayerName() != evaluatedAi.PlayerName() {
+			opponents = append(opponents, opponent)
+		}
+	}
+
+	if len(opponents) == 0 {
+		return nil, fmt.Errorf("the gauntlet scheduler needs at least 1 AI in the pool besides %s", evaluatedAi.PlayerName())
+	}
+
+	return &gauntletScheduler{evaluatedAi, opponents}, nil
+}
+
+func (scheduler *gauntletScheduler) Next(randGen *rand.Rand) []string {
+	return append([]string{scheduler.evaluatedAi.Descriptor()}, sample(randGen, scheduler.opponents, lineupSize-1)...)
+}
+
+// roundRobinScheduler plays every combination of different AIs of the pool
+// once, in random order, before playing any of them again
+type roundRobinScheduler struct {
+	pool         []poolAi
+	combinations [][]int
+	next         int
+}
+
+// combinations returns all the combinations of k of the numbers from 0 to n-1
+func combinations(n int, k int) [][]int {
+	result := make([][]int, 0)
+	combination := make([]int, 0, k)
+
+	var generate func(from int)
+	generate = func(from int) {
+		if len(combination) == k {
+			result = append(result, append([]int{}, combination...))
+			return
+		}
+
+		for i := from; i <= n-(k-len(combination)); i++ {
+			combination = append(combination, i)
+			generate(i + 1)
+			combination = combination[:len(combination)-1]
+		}
+	}
+
+	generate(0)
+
+	return result
+}
+
+// maxRoundRobinPool keeps the number of combinations of the round robin manageable
+const maxRoundRobinPool = 40
+
+func newRoundRobinScheduler(evaluatedAi *ai.Ai, pool []poolAi) (Scheduler, error) {
+	if len(pool) < lineupSize {
+		return nil, fmt.Errorf("the round-robin scheduler needs at least %d AIs in the pool, found %d", lineupSize, len(pool))
+	}
+
+	if len(pool) > maxRoundRobinPool {
+		return nil, fmt.Errorf("the round-robin scheduler supports at most %d AIs in the pool, found %d", maxRoundRobinPool, len(pool))
+	}
+
+	if weighted(pool) {
+		return nil, fmt.Errorf("the round-robin scheduler plays every combination equally often and does not support weights")
+	}
+
+	return &roundRobinScheduler{pool: pool, combinations: combinations(len(pool), lineupSize)}, nil
+}
+
+func (scheduler *roundRobinScheduler) Next(randGen *rand.Rand) []string {
+	if scheduler.next == 0 {
+		randGen.Shuffle(len(scheduler.combinations), func(i, j int) {
+			scheduler.combinations[i], scheduler.combinations[j] = scheduler.combinations[j], scheduler.combinations[i]
+		})
+	}
+
+	descriptors := make([]string, 0, lineupSize)
+	for _, i := range scheduler.combinations[scheduler.next] {
+		descriptors = append(descriptors, scheduler.pool[i].ai.Descriptor())
+	}
+
+	scheduler.next = (scheduler.next + 1) % len(scheduler.combinations)
+
+	return descriptors
+}
+
+// balancedScheduler picks the AIs that have played the fewest games so far
+// relative to their weights, so that every AI plays a number of games
+// proportional to its weight
+type balancedScheduler struct {
+	pool     []poolAi
+	numGames []int
+}
+
+func newBalancedScheduler(evaluatedAi *ai.Ai, pool []poolAi) (Scheduler, error) {
+	if len(pool) < lineupSize {
+		return nil, fmt.Errorf("the balanced scheduler needs at least %d AIs in the pool, found %d", lineupSize, len(pool))
+	}
+
+	return &balancedScheduler{pool: pool, numGames: make([]int, len(pool))}, nil
+}
+
+func (scheduler *balancedScheduler) Next(randGen *rand.Rand) []string {
+	load := func(i int) float64 {
+		return float64(scheduler.numGames[i]) / scheduler.pool[i].weight
+	}
+
+	// Shuffling before the stable sort breaks the ties at random
+	order := randGen.Perm(len(scheduler.pool))
+	sort.SliceStable(order, func(i, j int) bool {
+		return load(order[i]) < load(order[j])
+	})
+
+	descriptors := make([]string, 0, lineupSize)
+	for _, i := range order[:lineupSize] {
+		scheduler.numGames[i]++
+		descriptors = append(descriptors, scheduler.pool[i].ai.Descriptor())
+	}
+
+	return descriptors
+}
+
+const (
+	// meSlot is the slot of a lineup template played by the evaluated AI
+	meSlot = "me"
+	// againstSlot is the slot of a lineup template picked from the
+	// --against pool
+	againstSlot = "against"
+)
+
+// templateScheduler fills every slot of a lineup template with an AI picked
+// from the pool of that slot, e.g. me,strong,weak,weak
+type templateScheduler struct {
+	evaluatedAi *ai.Ai
+	slots       [][]poolAi
+}
+
+func newTemplateScheduler(evaluatedAi *ai.Ai, lineup []string, pools map[string][]poolAi) (Scheduler, error) {
+	if len(lineup) != lineupSize {
+		return nil, fmt.Errorf("the lineup '%s' has %d slots, expected %d", strings.Join(lineup, ","), len(lineup), lineupSize)
+	}
+
+	slots := make([][]poolAi, len(lineup))
+
+	for i, slot := range lineup {
+		if slot == meSlot {
+			continue
+		}
+
+		pool, ok := pools[slot]
+		if !ok {
+			return nil, fmt.Errorf("the lineup slot '%s' is not '%s', '%s' nor the name of a pool", slot, meSlot, againstSlot)
+		}
+
+		if len(pool) == 0 {
+			return nil, fmt.Errorf("the pool '%s' of the lineup matches no AI", slot)
+		}
+
+		slots[i] = pool
+	}
+
+	return &templateScheduler{evaluatedAi, slots}, nil
+}
+
+func (scheduler *templateScheduler) Next(randGen *rand.Rand) []string {
+	descriptors := make([]string, len(scheduler.slots))
+	used := make(map[string]bool)
+
+	for i, pool := range scheduler.slots {
+		if pool == nil {
+			descriptors[i] = scheduler.evaluatedAi.Descriptor()
+		} else {
+			descriptors[i] = pick(randGen, pool, used)
+		}
+
+		used[descriptors[i]] = true
+	}
+
+	return descriptors
+}
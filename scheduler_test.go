@@ -0,0 +1,303 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/albertsgrc/dojo/v2/ai"
+)
+
+// testPool returns a pool of AIs with the given weights
+func testPool(weights ...float64) []poolAi {
+	pool := make([]poolAi, len(weights))
+
+	for i, weight := range weights {
+		pool[i] = poolAi{&ai.Ai{Name: "AI", Version: i + 1}, weight}
+	}
+
+	return pool
+}
+
+func hasRepeated(descriptors []string) bool {
+	seen := make(map[string]bool)
+
+	for _, descriptor := range descriptors {
+		if seen[descriptor] {
+			return true
+		}
+		seen[descriptor] = true
+	}
+
+	return false
+}
+
+func TestParsePoolEntry(t *testing.T) {
+	tests := []struct {
+		Entry      string
+		Descriptor string
+		Weight     float64
+		Valid      bool
+	}{
+		{"Dummy", "Dummy", 1, true},
+		{"Dojo:-2..*3", "Dojo:-2..", 3, true},
+		{"Dummy*0.5", "Dummy", 0.5, true},
+		{"Dummy*0", "", 0, false},
+		{"Dummy*-1", "", 0, false},
+		{"Dummy*x", "", 0, false},
+		{"Dummy*NaN", "", 0, false},
+		{"Dummy*Inf", "", 0, false},
+		{"Dummy*-Inf", "", 0, false},
+		{"Dummy*2*3", "", 0, false},
+	}
+
+	for _, test := range tests {
+		descriptor, weight, err := parsePoolEntry(test.Entry)
+
+		if (err == nil) != test.Valid {
+			t.Error("Found error", err, "parsing", test.Entry, ", expected valid", test.Valid)
+		} else if descriptor != test.Descriptor || weight != test.Weight {
+			t.Error("Found", descriptor, weight, "parsing", test.Entry, ", expected", test.Descriptor, test.Weight)
+		}
+	}
+}
+
+func TestParsePools(t *testing.T) {
+	tests := []struct {
+		Definitions []string
+		Valid       bool
+	}{
+		{[]string{"strong=Dojo:-2..,Dojo_1*2", "weak=Dummy"}, true},
+		{[]string{"me=Dummy"}, false},
+		{[]string{"against=Dummy"}, false},
+		{[]string{" me =Dummy"}, false},
+		{[]string{"strong"}, false},
+		{[]string{"=Dummy"}, false},
+		{[]string{"strong= "}, false},
+	}
+
+	for _, test := range tests {
+		if _, err := parsePools(test.Definitions); (err == nil) != test.Valid {
+			t.Error("Found error", err, "parsing", test.Definitions, ", expected valid", test.Valid)
+		}
+	}
+
+	pools, _ := parsePools([]string{"strong=Dojo:-2.., Dojo_1*2", "strong=Dojo_3"})
+
+	if expected := []string{"Dojo:-2..", "Dojo_1*2", "Dojo_3"}; !reflect.DeepEqual(pools["strong"], expected) {
+		t.Error("Found pool", pools["strong"], ", expected", expected)
+	}
+}
+
+func TestPickAvoidsUsed(t *testing.T) {
+	randGen := rand.New(rand.NewSource(1))
+	pool := testPool(1, 100, 1)
+	used := map[string]bool{pool[1].ai.Descriptor(): true}
+
+	for i := 0; i < 100; i++ {
+		if descriptor := pick(randGen, pool, used); descriptor == pool[1].ai.Descriptor() {
+			t.Fatal("Picked the used AI", descriptor)
+		}
+	}
+
+	all := map[string]bool{}
+	for _, poolAi := range pool {
+		all[poolAi.ai.Descriptor()] = true
+	}
+
+	if descriptor := pick(randGen, pool, all); !all[descriptor] {
+		t.Error("Picked", descriptor, "which is not in the pool")
+	}
+}
+
+func TestSampleDoesNotRepeat(t *testing.T) {
+	randGen := rand.New(rand.NewSource(1))
+
+	tests := []struct {
+		Pool   []poolAi
+		Repeat bool
+	}{
+		{testPool(1, 1, 1, 1), false},
+		{testPool(1, 1, 1, 1, 1, 1), false},
+		{testPool(100, 1, 1, 1, 100), false},
+		{testPool(1, 1, 1), true},
+		{testPool(1), true},
+	}
+
+	for _, test := range tests {
+		for i := 0; i < 100; i++ {
+			descriptors := sample(randGen, test.Pool, lineupSize)
+
+			if len(descriptors) != lineupSize {
+				t.Fatal("Found", len(descriptors), "AIs, expected", lineupSize)
+			}
+
+			if hasRepeated(descriptors) != test.Repeat {
+				t.Fatal("Found", descriptors, "from a pool of", len(test.Pool), "AIs")
+			}
+		}
+	}
+}
+
+func TestTemplateSchedulerDoesNotRepeat(t *testing.T) {
+	randGen := rand.New(rand.NewSource(1))
+	me := &ai.Ai{Name: "Me", Version: 1}
+
+	tests := []struct {
+		Lineup []string
+		Pools  map[string][]poolAi
+		Repeat bool
+	}{
+		{[]string{"me", "all", "all", "all"}, map[string][]poolAi{"all": testPool(1, 1, 1)}, false},
+		{[]string{"me", "strong", "weak", "weak"}, map[string][]poolAi{"strong": testPool(1, 1), "weak": testPool(1, 1, 1)}, false},
+		{[]string{"me", "all", "all", "all"}, map[string][]poolAi{"all": testPool(1, 1)}, true},
+		{[]string{"me", "me", "all", "all"}, map[string][]poolAi{"all": testPool(1, 1, 1)}, true},
+	}
+
+	for _, test := range tests {
+		scheduler, err := newTemplateScheduler(me, test.Lineup, test.Pools)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 100; i++ {
+			descriptors := scheduler.Next(randGen)
+
+			if descriptors[0] != me.Descriptor() {
+				t.Fatal("Found", descriptors[0], "in the first slot, expected", me.Descriptor())
+			}
+
+			if hasRepeated(descriptors) != test.Repeat {
+				t.Fatal("Found", descriptors, "with the lineup", test.Lineup)
+			}
+		}
+	}
+}
+
+func TestNewTemplateScheduler(t *testing.T) {
+	me := &ai.Ai{Name: "Me", Version: 1}
+	pools := map[string][]poolAi{"strong": testPool(1), "empty": {}}
+
+	tests := []struct {
+		Lineup []string
+		Valid  bool
+	}{
+		{[]string{"me", "strong", "strong", "strong"}, true},
+		{[]string{"me", "strong", "strong"}, false},
+		{[]string{"me", "strong", "strong", "unknown"}, false},
+		{[]string{"me", "strong", "strong", "empty"}, false},
+	}
+
+	for _, test := range tests {
+		if _, err := newTemplateScheduler(me, test.Lineup, pools); (err == nil) != test.Valid {
+			t.Error("Found error", err, "with the lineup", test.Lineup, ", expected valid", test.Valid)
+		}
+	}
+}
+
+func TestBalancedSchedulerEqualizesGames(t *testing.T) {
+	randGen := rand.New(rand.NewSource(1))
+
+	for _, pool := range [][]poolAi{testPool(1, 1, 1, 1, 1), testPool(1, 1, 1, 1, 1, 1, 1), testPool(1, 1, 1, 1, 2, 2)} {
+		scheduler, err := newBalancedScheduler(pool[0].ai, pool)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		numGames := make(map[string]int)
+		for i := 0; i < 70; i++ {
+			descriptors := scheduler.Next(randGen)
+
+			if hasRepeated(descriptors) {
+				t.Fatal("Found", descriptors, ", expected different AIs")
+			}
+
+			for _, descriptor := range descriptors {
+				numGames[descriptor]++
+			}
+		}
+
+		totalWeight := 0.0
+		for _, poolAi := range pool {
+			totalWeight += poolAi.weight
+		}
+
+		// Every AI plays its share of the games, up to a game
+		for _, poolAi := range pool {
+			share := 70 * lineupSize * poolAi.weight / totalWeight
+
+			if actual := float64(numGames[poolAi.ai.Descriptor()]); math.Abs(actual-share) > 1 {
+				t.Error("Found", actual, "games of", poolAi.ai.Descriptor(), ", expected", share)
+			}
+		}
+	}
+}
+
+func TestRoundRobinSchedulerPlaysEveryCombination(t *testing.T) {
+	randGen := rand.New(rand.NewSource(1))
+
+	for _, n := range []int{4, 5, 7} {
+		pool := testPool(1, 1, 1, 1, 1, 1, 1)[:n]
+
+		scheduler, err := newRoundRobinScheduler(pool[0].ai, pool)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		numCombinations := len(combinations(n, lineupSize))
+
+		for cycle := 0; cycle < 3; cycle++ {
+			seen := make(map[string]bool)
+
+			for i := 0; i < numCombinations; i++ {
+				descriptors := scheduler.Next(randGen)
+				sort.Strings(descriptors)
+				combination := strings.Join(descriptors, ",")
+
+				if hasRepeated(descriptors) || seen[combination] {
+					t.Fatal("Found", combination, "again in cycle", cycle, "of", n, "AIs")
+				}
+
+				seen[combination] = true
+			}
+		}
+	}
+
+	if len(combinations(7, lineupSize)) != 35 {
+		t.Error("Found", len(combinations(7, lineupSize)), "combinations of 4 of 7 AIs, expected 35")
+	}
+}
+
+func TestGauntletSchedulerSeatsEvaluatedAi(t *testing.T) {
+	randGen := rand.New(rand.NewSource(1))
+
+	for _, pool := range [][]poolAi{testPool(1, 1), testPool(1, 1, 1, 1), testPool(1, 1, 1, 1, 1, 1)} {
+		evaluatedAi := pool[0].ai
+
+		scheduler, err := newGauntletScheduler(evaluatedAi, pool)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < 100; i++ {
+			descriptors := scheduler.Next(randGen)
+
+			if descriptors[0] != evaluatedAi.Descriptor() {
+				t.Fatal("Found", descriptors, ", expected", evaluatedAi.Descriptor(), "in the first seat")
+			}
+
+			for _, descriptor := range descriptors[1:] {
+				if descriptor == evaluatedAi.Descriptor() {
+					t.Fatal("Found", descriptors, ", expected", evaluatedAi.Descriptor(), "only once")
+				}
+			}
+		}
+	}
+
+	if _, err := newGauntletScheduler(testPool(1)[0].ai, testPool(1)); err == nil {
+		t.Error("Created a gauntlet without opponents")
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/albertsgrc/dojo/v2/ai"
+)
+
+// sessionsDir is the folder where every evaluation is recorded, one file per
+// session, so that it can be resumed
+const sessionsDir = ".dojo/sessions"
+
+// SessionStatus ...
+type SessionStatus string
+
+const (
+	// SessionRunning is the status of a session being played, or of one
+	// whose process was killed before it could record anything else
+	SessionRunning SessionStatus = "running"
+	// SessionInterrupted is the status of a session stopped by a signal
+	SessionInterrupted SessionStatus = "interrupted"
+	// SessionStopped is the status of a session stopped by an error, such as
+	// too many failed games
+	SessionStopped SessionStatus = "stopped"
+	// SessionFinished is the status of a session that played all its games
+	// or whose SPRT decided
+	SessionFinished SessionStatus = "finished"
+)
+
+// Session is the record of an evaluation, with everything needed to resume it
+type Session struct {
+	ID   string
+	Time time.Time
+	// Ai is the descriptor of the evaluated AI
+	Ai string
+	// Player is the player name of the evaluated AI
+	Player  string
+	Options EvaluateOptions
+	// Games contains the id of every game played so far by its index in the
+	// schedule generated from the selection seed
+	Games map[int]string
+	// Order contains the indices of Games in the order the games finished,
+	// which is the order their results are rated in
+	Order []int
+	// Reused contains the ids of the games played before the session that
+	// were included in its ranking
+	Reused []string
+	// Elapsed is the time spent playing the session, without the time it was
+	// interrupted
+	Elapsed time.Duration
+	Status  SessionStatus
+	// Pid is the id of the process that plays the session
+	Pid int
+}
+
+// NewSession creates the record of a new evaluation
+func NewSession(evaluatedAi *ai.Ai, options EvaluateOptions) *Session {
+	return &Session{
+		ID:      newGameID(),
+		Time:    time.Now(),
+		Ai:      evaluatedAi.Descriptor(),
+		Player:  evaluatedAi.PlayerName(),
+		Options: options,
+		Games:   make(map[int]string),
+		Status:  SessionRunning,
+		Pid:     os.Getpid(),
+	}
+}
+
+func sessionFile(id string) string {
+	return filepath.Join(sessionsDir, id+".json")
+}
+
+// Save records the current state of the session
+func (session *Session) Save() error {
+	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Writing to a temporary file first keeps the previous record intact if
+	// the process is killed while saving
+	tmpFile := sessionFile(session.ID) + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, content, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, sessionFile(session.ID))
+}
+
+// Playing returns whether the session is being played by another process
+// that is still alive
+func (session *Session) Playing() bool {
+	if session.Status != SessionRunning || session.Pid <= 0 || session.Pid == os.Getpid() {
+		return false
+	}
+
+	err := syscall.Kill(session.Pid, 0)
+
+	return err == nil || err == syscall.EPERM
+}
+
+// Resumable returns whether the session has games left to play and no other
+// process is playing it
+func (session *Session) Resumable() bool {
+	return session.Status != SessionFinished && !session.Playing()
+}
+
+// Resume takes over the session from the process that played it before
+func (session *Session) Resume() error {
+	session.Status = SessionRunning
+	session.Pid = os.Getpid()
+
+	return session.Save()
+}
+
+// PlayedIndices returns the schedule indices of the games played, in the
+// order they finished
+func (session *Session) PlayedIndices() []int {
+	if len(session.Order) == len(session.Games) {
+		return session.Order
+	}
+
+	// Sessions recorded without the order are rated in schedule order
+	indices := make([]int, 0, len(session.Games))
+	for index := range session.Games {
+		indices = append(indices, index)
+	}
+
+	sort.Ints(indices)
+
+	return indices
+}
+
+// sessionIDs returns the ids of all the recorded sessions, from oldest to newest
+func sessionIDs() ([]string, error) {
+	files, err := ioutil.ReadDir(sessionsDir)
+
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(files))
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
+			ids = append(ids, strings.TrimSuffix(file.Name(), ".json"))
+		}
+	}
+
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+// loadSession loads a recorded session given its id
+func loadSession(id string) (*Session, error) {
+	content, err := ioutil.ReadFile(sessionFile(id))
+
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("session '%s' not found", id)
+	} else if err != nil {
+		return nil, err
+	}
+
+	session := new(Session)
+	err = json.Unmarshal(content, session)
+
+	return session, err
+}
+
+// loadResumableSession loads the session with the given id, or the last one
+// that can be resumed if id is empty
+func loadResumableSession(id string) (*Session, error) {
+	if len(id) > 0 {
+		session, err := loadSession(id)
+		if err != nil {
+			return nil, err
+		}
+
+		if session.Playing() {
+			return nil, fmt.Errorf("session '%s' is being played by process %d", id, session.Pid)
+		}
+
+		if !session.Resumable() {
+			return nil, fmt.Errorf("session '%s' is already finished", id)
+		}
+
+		return session, nil
+	}
+
+	ids, err := sessionIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(ids) - 1; i >= 0; i-- {
+		session, err := loadSession(ids[i])
+
+		if err == nil && session.Resumable() {
+			return session, nil
+		}
+	}
+
+	return nil, fmt.Errorf("there is no evaluation session to resume")
+}
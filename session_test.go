@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestPlayedIndices(t *testing.T) {
+	tests := []struct {
+		Session  Session
+		Expected []int
+	}{
+		{Session{Games: map[int]string{}}, nil},
+		{Session{Games: map[int]string{0: "a", 3: "b", 1: "c"}, Order: []int{3, 0, 1}}, []int{3, 0, 1}},
+		{Session{Games: map[int]string{0: "a", 3: "b", 1: "c"}}, []int{0, 1, 3}},
+	}
+
+	for _, test := range tests {
+		if indices := test.Session.PlayedIndices(); !reflect.DeepEqual(indices, test.Expected) {
+			t.Error("Found", indices, ", expected", test.Expected)
+		}
+	}
+}
+
+// deadPid returns the id of a process that has already finished
+func deadPid(t *testing.T) int {
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	return cmd.Process.Pid
+}
+
+func TestResumable(t *testing.T) {
+	tests := []struct {
+		Status    SessionStatus
+		Pid       int
+		Resumable bool
+	}{
+		{SessionInterrupted, os.Getppid(), true},
+		{SessionStopped, 0, true},
+		{SessionFinished, 0, false},
+		{SessionRunning, os.Getpid(), true},
+		{SessionRunning, os.Getppid(), false},
+		{SessionRunning, deadPid(t), true},
+	}
+
+	for _, test := range tests {
+		session := Session{Status: test.Status, Pid: test.Pid}
+
+		if session.Resumable() != test.Resumable {
+			t.Error("Found resumable", session.Resumable(), "with status", test.Status, "and pid", test.Pid, ", expected", test.Resumable)
+		}
+	}
+}
+
+func TestLoadResumableSession(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dojo-sessions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	sessions := []Session{
+		{ID: "1", Status: SessionInterrupted},
+		{ID: "2", Status: SessionRunning, Pid: deadPid(t)},
+		{ID: "3", Status: SessionRunning, Pid: os.Getppid()},
+		{ID: "4", Status: SessionFinished},
+	}
+
+	for i := range sessions {
+		if err := sessions[i].Save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		ID       string
+		Expected string
+	}{
+		{"", "2"},
+		{"1", "1"},
+		{"3", ""},
+		{"4", ""},
+		{"5", ""},
+	}
+
+	for _, test := range tests {
+		session, err := loadResumableSession(test.ID)
+
+		if len(test.Expected) == 0 && err == nil {
+			t.Error("Resumed session", session.ID, "given", test.ID, ", expected an error")
+		} else if len(test.Expected) > 0 && (err != nil || session.ID != test.Expected) {
+			t.Error("Found", session, err, "given", test.ID, ", expected session", test.Expected)
+		}
+	}
+}
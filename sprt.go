@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/albertsgrc/dojo/v2/rating"
+)
+
+// SPRTDecision ...
+type SPRTDecision string
+
+const (
+	// SPRTContinue means that more games are needed
+	SPRTContinue SPRTDecision = ""
+	// SPRTAcceptH0 means that the Elo difference is elo0 or less
+	SPRTAcceptH0 SPRTDecision = "H0 accepted"
+	// SPRTAcceptH1 means that the Elo difference is elo1 or more
+	SPRTAcceptH1 SPRTDecision = "H1 accepted"
+)
+
+// SPRT is a sequential probability ratio test of the hypotheses H0, the Elo
+// difference is elo0, against H1, the Elo difference is elo1. Every game is an
+// observation between 0 (lost) and 1 (won), and the log-likelihood ratio is
+// computed with the normal approximation of the generalized SPRT.
+type SPRT struct {
+	Elo0, Elo1  float64
+	Alpha, Beta float64
+	// LLRs contains the log-likelihood ratio after every observation
+	LLRs     []float64
+	Decision SPRTDecision
+	n        int
+	sum      float64
+	sumSq    float64
+}
+
+// parseSPRT parses the parameters of an SPRT, e.g.
+// "elo0=0,elo1=20,alpha=0.05,beta=0.05", where missing parameters take those values
+func parseSPRT(s string) (*SPRT, error) {
+	sprt := &SPRT{Elo0: 0, Elo1: 20, Alpha: 0.05, Beta: 0.05}
+
+	for _, field := range strings.Split(s, ",") {
+		split := strings.SplitN(strings.TrimSpace(field), "=", 2)
+
+		if len(split) != 2 {
+			return nil, fmt.Errorf("invalid SPRT parameter '%s', expected name=value", field)
+		}
+
+		value, err := strconv.ParseFloat(split[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of the SPRT parameter '%s'", field)
+		}
+
+		switch split[0] {
+		case "elo0":
+			sprt.Elo0 = value
+		case "elo1":
+			sprt.Elo1 = value
+		case "alpha":
+			sprt.Alpha = value
+		case "beta":
+			sprt.Beta = value
+		default:
+			return nil, fmt.Errorf("unknown SPRT parameter '%s', use elo0, elo1, alpha or beta", split[0])
+		}
+	}
+
+	if sprt.Elo0 >= sprt.Elo1 {
+		return nil, fmt.Errorf("elo0 must be less than elo1")
+	}
+
+	if sprt.Alpha <= 0 || sprt.Alpha >= 1 || sprt.Beta <= 0 || sprt.Beta >= 1 {
+		return nil, fmt.Errorf("alpha and beta must be between 0 and 1")
+	}
+
+	return sprt, nil
+}
+
+func (sprt *SPRT) String() string {
+	return fmt.Sprintf("elo0=%g,elo1=%g,alpha=%g,beta=%g", sprt.Elo0, sprt.Elo1, sprt.Alpha, sprt.Beta)
+}
+
+// eloScore returns the expected score of a player that is elo points better than its opponent
+func eloScore(elo float64) float64 {
+	return 1 / (1 + math.Pow(10, -elo/400))
+}
+
+// Bounds returns the log-likelihood ratios below which H0 is accepted and
+// above which H1 is accepted
+func (sprt *SPRT) Bounds() (float64, float64) {
+	return math.Log(sprt.Beta / (1 - sprt.Alpha)), math.Log((1 - sprt.Beta) / sprt.Alpha)
+}
+
+// LLR returns the current log-likelihood ratio
+func (sprt *SPRT) LLR() float64 {
+	if sprt.n == 0 {
+		return 0
+	}
+
+	n := float64(sprt.n)
+	mean := sprt.sum / n
+	variance := sprt.sumSq/n - mean*mean
+
+	// All the results are equal so far, which carries no information about the variance
+	if variance <= 1e-12 {
+		return 0
+	}
+
+	s0, s1 := eloScore(sprt.Elo0), eloScore(sprt.Elo1)
+
+	return n * (s1 - s0) * (2*mean - s0 - s1) / (2 * variance)
+}
+
+// Add adds the result of a game, between 0 (lost) and 1 (won), and returns the
+// decision of the test. Results added once the test has decided are ignored.
+func (sprt *SPRT) Add(result float64) SPRTDecision {
+	if sprt.Decision != SPRTContinue {
+		return sprt.Decision
+	}
+
+	sprt.n++
+	sprt.sum += result
+	sprt.sumSq += result * result
+
+	llr := sprt.LLR()
+	sprt.LLRs = append(sprt.LLRs, llr)
+
+	lower, upper := sprt.Bounds()
+
+	if llr >= upper {
+		sprt.Decision = SPRTAcceptH1
+	} else if llr <= lower {
+		sprt.Decision = SPRTAcceptH0
+	}
+
+	return sprt.Decision
+}
+
+// gameOutcome returns the fraction of its opponents that the player beat in
+// a game, where ties count as half, or false if the player did not play it
+func gameOutcome(player string, gameResult GameResult) (float64, bool) {
+	scores := gameScores(gameResult)
+
+	score, ok := scores[player]
+	if !ok || len(scores) < 2 {
+		return 0, false
+	}
+
+	result := 0.0
+	for opponent, opponentScore := range scores {
+		if opponent != player {
+			result += rating.Outcome(score, opponentScore)
+		}
+	}
+
+	return result / float64(len(scores)-1), true
+}
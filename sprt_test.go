@@ -0,0 +1,120 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseSPRT(t *testing.T) {
+	tests := []struct {
+		SPRT     string
+		Expected SPRT
+		Valid    bool
+	}{
+		{"elo0=0,elo1=20,alpha=0.05,beta=0.05", SPRT{Elo0: 0, Elo1: 20, Alpha: 0.05, Beta: 0.05}, true},
+		{"elo1=10", SPRT{Elo0: 0, Elo1: 10, Alpha: 0.05, Beta: 0.05}, true},
+		{"elo0=-5, elo1=5, alpha=0.1", SPRT{Elo0: -5, Elo1: 5, Alpha: 0.1, Beta: 0.05}, true},
+		{"elo0=20", SPRT{}, false},
+		{"elo0=10,elo1=10", SPRT{}, false},
+		{"elo0=15,elo1=10", SPRT{}, false},
+		{"elo2=10", SPRT{}, false},
+		{"elo1", SPRT{}, false},
+		{"elo1=", SPRT{}, false},
+		{"elo1=x", SPRT{}, false},
+		{"alpha=0", SPRT{}, false},
+		{"beta=1", SPRT{}, false},
+		{"", SPRT{}, false},
+	}
+
+	for _, test := range tests {
+		sprt, err := parseSPRT(test.SPRT)
+
+		if (err == nil) != test.Valid {
+			t.Error("Found error", err, "parsing", test.SPRT, ", expected valid", test.Valid)
+		} else if test.Valid && (sprt.Elo0 != test.Expected.Elo0 || sprt.Elo1 != test.Expected.Elo1 ||
+			sprt.Alpha != test.Expected.Alpha || sprt.Beta != test.Expected.Beta) {
+			t.Error("Found", sprt, "parsing", test.SPRT, ", expected", &test.Expected)
+		}
+	}
+}
+
+// sprtWith returns an SPRT with the default parameters that has observed the
+// given number of wins, draws and losses
+func sprtWith(wins int, draws int, losses int) *SPRT {
+	sprt := &SPRT{Elo0: 0, Elo1: 20, Alpha: 0.05, Beta: 0.05}
+
+	sprt.n = wins + draws + losses
+	sprt.sum = float64(wins) + 0.5*float64(draws)
+	sprt.sumSq = float64(wins) + 0.25*float64(draws)
+
+	return sprt
+}
+
+func TestSPRTLLR(t *testing.T) {
+	tests := []struct {
+		Wins, Draws, Losses int
+		Expected            float64
+	}{
+		{0, 0, 0, 0},
+		{10, 0, 0, 0},
+		{60, 20, 20, 3.3355},
+		{55, 10, 35, 1.1450},
+		{50, 0, 50, -0.1653},
+		{30, 20, 50, -1.7307},
+	}
+
+	for _, test := range tests {
+		if llr := sprtWith(test.Wins, test.Draws, test.Losses).LLR(); math.Abs(llr-test.Expected) > 1e-4 {
+			t.Error("Found LLR", llr, "with", test.Wins, test.Draws, test.Losses, ", expected", test.Expected)
+		}
+	}
+
+	lower, upper := sprtWith(0, 0, 0).Bounds()
+	if math.Abs(lower+2.9444) > 1e-4 || math.Abs(upper-2.9444) > 1e-4 {
+		t.Error("Found bounds", lower, upper, ", expected -2.9444 and 2.9444")
+	}
+}
+
+func TestSPRTAdd(t *testing.T) {
+	tests := []struct {
+		// Pattern is repeated until the test decides
+		Pattern  []float64
+		Expected SPRTDecision
+	}{
+		{[]float64{1, 1, 0.5, 0}, SPRTAcceptH1},
+		{[]float64{1, 0, 0, 0.5}, SPRTAcceptH0},
+		{[]float64{1, 0}, SPRTAcceptH0},
+	}
+
+	for _, test := range tests {
+		sprt := &SPRT{Elo0: 0, Elo1: 20, Alpha: 0.05, Beta: 0.05}
+		lower, upper := sprt.Bounds()
+
+		for i := 0; i < 100000 && sprt.Decision == SPRTContinue; i++ {
+			sprt.Add(test.Pattern[i%len(test.Pattern)])
+		}
+
+		if sprt.Decision != test.Expected {
+			t.Error("Found decision", sprt.Decision, "with the pattern", test.Pattern, ", expected", test.Expected)
+			continue
+		}
+
+		// The test decides as soon as the LLR crosses a bound, and not before
+		last := len(sprt.LLRs) - 1
+		for i, llr := range sprt.LLRs[:last] {
+			if llr <= lower || llr >= upper {
+				t.Error("Found LLR", llr, "out of the bounds after", i+1, "games with the pattern", test.Pattern)
+				break
+			}
+		}
+
+		if llr := sprt.LLRs[last]; (sprt.Decision == SPRTAcceptH1 && llr < upper) || (sprt.Decision == SPRTAcceptH0 && llr > lower) {
+			t.Error("Found final LLR", llr, "with the decision", sprt.Decision)
+		}
+
+		// Results after the decision are ignored
+		if sprt.Add(1) != test.Expected || len(sprt.LLRs) != last+1 {
+			t.Error("Found a result added after the decision with the pattern", test.Pattern)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// gamesDir is the folder where every played game is recorded, one folder per game
+const gamesDir = ".dojo/games"
+
+const gameFileName = "game.json"
+
+func newGameID() string {
+	return fmt.Sprintf("%s-%04x", time.Now().Format("20060102-150405.000"), rand.Intn(0x10000))
+}
+
+func gameDir(id string) string {
+	return filepath.Join(gamesDir, id)
+}
+
+func saveGameResult(gameResult GameResult) error {
+	if err := os.MkdirAll(gameDir(gameResult.ID), 0755); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(gameResult, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(gameDir(gameResult.ID), gameFileName), content, 0644)
+}
+
+// fileHash returns the hash of the content of a file, which identifies the
+// version of an AI or a game configuration
+func fileHash(fileName string) (string, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(content)
+
+	return hex.EncodeToString(hash[:8]), nil
+}
+
+func copyFile(from string, to string) error {
+	content, err := ioutil.ReadFile(from)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(to, content, 0644)
+}
+
+func loadGameResultFile(fileName string) (GameResult, error) {
+	var gameResult GameResult
+
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return gameResult, err
+	}
+
+	err = json.Unmarshal(content, &gameResult)
+
+	return gameResult, err
+}
+
+// gameIDs returns the ids of all the recorded games, from oldest to newest
+func gameIDs() ([]string, error) {
+	files, err := ioutil.ReadDir(gamesDir)
+
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(files))
+	for _, file := range files {
+		if file.IsDir() {
+			ids = append(ids, file.Name())
+		}
+	}
+
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+// loadGameResult loads a recorded game given its id, or the last game played
+// if id is "last"
+func loadGameResult(id string) (GameResult, error) {
+	if id == "last" {
+		ids, err := gameIDs()
+		if err != nil {
+			return GameResult{}, err
+		}
+
+		if len(ids) == 0 {
+			return GameResult{}, fmt.Errorf("no games have been recorded yet")
+		}
+
+		id = ids[len(ids)-1]
+	}
+
+	gameResult, err := loadGameResultFile(filepath.Join(gameDir(id), gameFileName))
+
+	if os.IsNotExist(err) {
+		return gameResult, fmt.Errorf("game '%s' not found", id)
+	}
+
+	return gameResult, err
+}
+
+// loadGameResults loads all the recorded games, from oldest to newest,
+// skipping the ones that cannot be read
+func loadGameResults() ([]GameResult, error) {
+	ids, err := gameIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	gameResults := make([]GameResult, 0, len(ids))
+
+	for _, id := range ids {
+		gameResult, err := loadGameResultFile(filepath.Join(gameDir(id), gameFileName))
+
+		if err == nil {
+			gameResults = append(gameResults, gameResult)
+		}
+	}
+
+	return gameResults, nil
+}
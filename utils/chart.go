@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/text"
+)
+
+// Series ...
+type Series struct {
+	Name   string
+	Values []float64
+}
+
+var seriesColors = []text.Color{
+	text.FgRed, text.FgGreen, text.FgYellow, text.FgBlue, text.FgMagenta, text.FgCyan,
+}
+
+func seriesColor(i int) text.Color {
+	return seriesColors[i%len(seriesColors)]
+}
+
+// Chart renders the series as a line chart of the given width and height,
+// where the x axis is the index of the values
+func Chart(series []Series, width int, height int) string {
+	numValues := 0
+	minValue, maxValue := math.Inf(1), math.Inf(-1)
+
+	for _, s := range series {
+		if len(s.Values) > numValues {
+			numValues = len(s.Values)
+		}
+
+		for _, value := range s.Values {
+			minValue = math.Min(minValue, value)
+			maxValue = math.Max(maxValue, value)
+		}
+	}
+
+	if numValues == 0 {
+		return "No data to plot\n"
+	}
+
+	if maxValue == minValue {
+		maxValue = minValue + 1
+	}
+
+	if numValues < width {
+		width = numValues
+	}
+
+	grid := make([][]int, height)
+	for row := range grid {
+		grid[row] = make([]int, width)
+		for col := range grid[row] {
+			grid[row][col] = -1
+		}
+	}
+
+	for i, s := range series {
+		for col := 0; col < width; col++ {
+			index := 0
+			if width > 1 {
+				index = col * (numValues - 1) / (width - 1)
+			}
+
+			if index >= len(s.Values) {
+				continue
+			}
+
+			row := int(math.Round((s.Values[index] - minValue) / (maxValue - minValue) * float64(height-1)))
+			grid[height-1-row][col] = i
+		}
+	}
+
+	labelWidth := len(fmt.Sprintf("%.0f", maxValue))
+	if minLabelWidth := len(fmt.Sprintf("%.0f", minValue)); minLabelWidth > labelWidth {
+		labelWidth = minLabelWidth
+	}
+
+	var out strings.Builder
+
+	for row := 0; row < height; row++ {
+		label := ""
+		if row == 0 || row == height-1 || row == height/2 {
+			value := maxValue - (maxValue-minValue)*float64(row)/float64(height-1)
+			label = fmt.Sprintf("%.0f", value)
+			if label == "-0" {
+				label = "0"
+			}
+		}
+
+		out.WriteString(text.AlignRight.Apply(label, labelWidth) + " ┤")
+
+		for _, i := range grid[row] {
+			if i < 0 {
+				out.WriteRune(' ')
+			} else {
+				out.WriteString(seriesColor(i).Sprint("•"))
+			}
+		}
+
+		out.WriteRune('\n')
+	}
+
+	out.WriteString(strings.Repeat(" ", labelWidth) + " └" + strings.Repeat("─", width) + "\n")
+
+	lastLabel := fmt.Sprint(numValues)
+	padding := width - 1 - len(lastLabel)
+	if padding < 1 {
+		padding = 1
+	}
+	out.WriteString(strings.Repeat(" ", labelWidth+2) + "1" + strings.Repeat(" ", padding) + lastLabel + "\n")
+
+	legend := make([]string, len(series))
+	for i, s := range series {
+		legend[i] = seriesColor(i).Sprint("• ") + s.Name
+	}
+	out.WriteString(strings.Repeat(" ", labelWidth+2) + strings.Join(legend, "   ") + "\n")
+
+	return out.String()
+}
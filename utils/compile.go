@@ -5,9 +5,9 @@ import (
 	"os/exec"
 )
 
-// Compile ...
-func Compile() error {
-	cmd := exec.Command("make")
+// Compile runs make with the given targets, or the default target if none
+func Compile(targets ...string) error {
+	cmd := exec.Command("make", targets...)
 
 	cmd.Stderr = os.Stderr
 	err := cmd.Run()
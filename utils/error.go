@@ -12,3 +12,9 @@ func Error(message string) {
 		"💥 ", message))
 
 }
+
+// Warning ...
+func Warning(message string) {
+	fmt.Println(text.Colors{text.BgBlack, text.FgYellow, text.Bold}.Sprint(
+		"⚠️  ", message))
+}
@@ -1,70 +1,215 @@
 package utils
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
 	"os/exec"
+	"strconv"
+	"strings"
 	"sync"
+	"syscall"
+	"time"
 )
 
-func execCapture(app string, args ...string) (string, string, error) {
-	cmd := exec.Command(app, args...)
+// ErrTimeout is returned by Exec when the process exceeds its wall-clock timeout
+var ErrTimeout = errors.New("process timed out")
+
+// ErrCPULimit is returned by Exec when the process is killed for exceeding
+// its CPU time limit
+var ErrCPULimit = errors.New("process exceeded its CPU time limit")
+
+// Limits ...
+type Limits struct {
+	// Timeout is the wall-clock time the process is allowed to run, 0 means no limit
+	Timeout time.Duration
+	// MemoryMB is the maximum virtual memory of the process in megabytes, 0 means no limit
+	MemoryMB int
+	// CPUSeconds is the maximum CPU time of the process in seconds, 0 means no limit
+	CPUSeconds int
+}
+
+// ExecOptions ...
+type ExecOptions struct {
+	PrintOutput bool
+	Limits      Limits
+	// Env contains environment variables added to the ones of the current process
+	Env []string
+	// OnStderrLine is called with every line the process writes to stderr, as
+	// soon as it is written
+	OnStderrLine func(line string)
+}
+
+// withResourceLimits wraps the command in a shell that sets the requested
+// rlimits before replacing itself with the actual process
+func withResourceLimits(app string, limits Limits, args []string) (string, []string) {
+	script := ""
+
+	if limits.MemoryMB > 0 {
+		script += "ulimit -v " + strconv.Itoa(limits.MemoryMB*1024) + " && "
+	}
+
+	if limits.CPUSeconds > 0 {
+		script += "ulimit -t " + strconv.Itoa(limits.CPUSeconds) + " && "
+	}
+
+	if len(script) == 0 {
+		return app, args
+	}
+
+	return "sh", append([]string{"-c", script + `exec "$0" "$@"`, app}, args...)
+}
+
+// running contains the process groups started by Exec that have not finished
+var running = struct {
+	sync.Mutex
+	groups map[int]bool
+	killed bool
+}{groups: make(map[int]bool)}
+
+// KillRunning kills the process groups of all the running processes started
+// by Exec, and of every process started afterwards
+func KillRunning() {
+	running.Lock()
+	defer running.Unlock()
+
+	running.killed = true
+
+	for pid := range running.groups {
+		syscall.Kill(-pid, syscall.SIGKILL)
+	}
+}
+
+func startRunning(pid int) {
+	running.Lock()
+	defer running.Unlock()
+
+	running.groups[pid] = true
+
+	if running.killed {
+		syscall.Kill(-pid, syscall.SIGKILL)
+	}
+}
+
+func finishRunning(pid int) {
+	running.Lock()
+	defer running.Unlock()
+
+	delete(running.groups, pid)
+}
+
+// Exec runs app with the given arguments and returns its stdout and stderr.
+// The output captured so far is returned even if the process fails.
+func Exec(app string, options ExecOptions, args ...string) (string, string, error) {
+	name, args := withResourceLimits(app, options.Limits, args)
+	cmd := exec.Command(name, args...)
+
+	// Run the process in its own group so that everything it spawns can be
+	// killed together on timeout
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	if len(options.Env) > 0 {
+		cmd.Env = append(os.Environ(), options.Env...)
+	}
 
 	var stdoutBuf, stderrBuf bytes.Buffer
-	stdoutIn, _ := cmd.StdoutPipe()
-	stderrIn, _ := cmd.StderrPipe()
-
-	var errStdout, errStderr error
-	stdout := io.MultiWriter(os.Stdout, &stdoutBuf)
-	stderr := io.MultiWriter(os.Stderr, &stderrBuf)
-	err := cmd.Start()
-	if err != nil {
-		log.Fatalf("cmd.Start() failed with '%s'\n", err)
+	var stdout, stderr io.Writer = &stdoutBuf, &stderrBuf
+
+	if options.PrintOutput {
+		stdout = io.MultiWriter(os.Stdout, &stdoutBuf)
+		stderr = io.MultiWriter(os.Stderr, &stderrBuf)
+	}
+
+	cmd.Stdout = stdout
+
+	var stderrPipe io.ReadCloser
+	if options.OnStderrLine != nil {
+		pipe, err := cmd.StderrPipe()
+		if err != nil {
+			return "", "", err
+		}
+		stderrPipe = pipe
+	} else {
+		cmd.Stderr = stderr
+	}
+
+	if err := cmd.Start(); err != nil {
+		return "", "", fmt.Errorf("could not start %s: %s", app, err)
 	}
 
-	var wg sync.WaitGroup
-	wg.Add(1)
+	startRunning(cmd.Process.Pid)
+	defer finishRunning(cmd.Process.Pid)
+
+	var mutex sync.Mutex
+	timedOut := false
 
-	go func() {
-		_, errStdout = io.Copy(stdout, stdoutIn)
-		wg.Done()
-	}()
+	if options.Limits.Timeout > 0 {
+		timer := time.AfterFunc(options.Limits.Timeout, func() {
+			mutex.Lock()
+			timedOut = true
+			mutex.Unlock()
 
-	_, errStderr = io.Copy(stderr, stderrIn)
-	wg.Wait()
+			syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		})
+		defer timer.Stop()
+	}
 
-	err = cmd.Wait()
-	if err != nil {
-		return "", "", err
+	if stderrPipe != nil {
+		streamLines(stderrPipe, stderr, options.OnStderrLine)
 	}
-	if errStdout != nil || errStderr != nil {
-		return "", "", fmt.Errorf("failed to capture stdout or stderr")
+
+	err := cmd.Wait()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if timedOut {
+		err = ErrTimeout
+	} else if options.Limits.CPUSeconds > 0 && killedByCPULimit(err) {
+		err = ErrCPULimit
 	}
 
 	return stdoutBuf.String(), stderrBuf.String(), err
 }
 
-func execOutput(app string, args ...string) (string, string, error) {
-	cmd := exec.Command(app, args...)
-
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
+// killedByCPULimit returns whether the error of a process with a CPU time
+// limit is caused by the limit, which raises SIGXCPU once the soft limit is
+// exceeded and SIGKILL once the hard one is
+func killedByCPULimit(err error) bool {
+	exitErr, ok := err.(*exec.ExitError)
+	if !ok {
+		return false
+	}
 
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return false
+	}
 
-	err := cmd.Run()
+	running.Lock()
+	defer running.Unlock()
 
-	return stdout.String(), stderr.String(), err
+	// The processes killed by KillRunning are not over the limit
+	return status.Signal() == syscall.SIGXCPU || (status.Signal() == syscall.SIGKILL && !running.killed)
 }
 
-// Exec ...
-func Exec(app string, printOutput bool, args ...string) (string, string, error) {
-	if printOutput {
-		return execCapture(app, args...)
+// streamLines copies reader to writer until EOF, calling onLine with every line
+func streamLines(reader io.Reader, writer io.Writer, onLine func(line string)) {
+	bufferedReader := bufio.NewReader(reader)
+
+	for {
+		line, err := bufferedReader.ReadString('\n')
+
+		if len(line) > 0 {
+			writer.Write([]byte(line))
+			onLine(strings.TrimRight(line, "\n"))
+		}
+
+		if err != nil {
+			return
+		}
 	}
-	return execOutput(app, args...)
 }
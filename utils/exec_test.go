@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestWithResourceLimits(t *testing.T) {
+	tests := []struct {
+		Limits   Limits
+		Expected []string
+	}{
+		{Limits{}, []string{"Game", "-s", "1"}},
+		{Limits{Timeout: time.Second}, []string{"Game", "-s", "1"}},
+		{Limits{MemoryMB: 2}, []string{"sh", "-c", `ulimit -v 2048 && exec "$0" "$@"`, "Game", "-s", "1"}},
+		{Limits{CPUSeconds: 3}, []string{"sh", "-c", `ulimit -t 3 && exec "$0" "$@"`, "Game", "-s", "1"}},
+		{Limits{MemoryMB: 2, CPUSeconds: 3}, []string{"sh", "-c", `ulimit -v 2048 && ulimit -t 3 && exec "$0" "$@"`, "Game", "-s", "1"}},
+	}
+
+	for _, test := range tests {
+		name, args := withResourceLimits("Game", test.Limits, []string{"-s", "1"})
+
+		if command := append([]string{name}, args...); !reflect.DeepEqual(command, test.Expected) {
+			t.Error("Found", command, "with limits", test.Limits, ", expected", test.Expected)
+		}
+	}
+}
+
+// alive returns whether a process is still running after a while, zombies
+// count as finished since they may never be reaped in a container
+func alive(pid int) bool {
+	for i := 0; i < 50; i++ {
+		stat, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+		if err != nil || strings.Contains(string(stat), ") Z ") {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return true
+}
+
+func TestExecTimeoutKillsProcessGroup(t *testing.T) {
+	start := time.Now()
+	stdout, _, err := Exec("sh", ExecOptions{Limits: Limits{Timeout: 200 * time.Millisecond}}, "-c", "sleep 30 & echo $!; wait")
+
+	if err != ErrTimeout {
+		t.Fatal("Found error", err, ", expected", ErrTimeout)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Error("Found the process killed after", elapsed)
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(stdout))
+	if err != nil {
+		t.Fatal("Found output", stdout, ", expected the pid of the child")
+	}
+
+	if alive(pid) {
+		syscall.Kill(pid, syscall.SIGKILL)
+		t.Error("The child process", pid, "was not killed")
+	}
+}
+
+func TestExecCPULimit(t *testing.T) {
+	_, _, err := Exec("sh", ExecOptions{Limits: Limits{Timeout: 20 * time.Second, CPUSeconds: 1}}, "-c", "while :; do :; done")
+
+	if err != ErrCPULimit {
+		t.Error("Found error", err, ", expected", ErrCPULimit)
+	}
+}
+
+func TestExecExitError(t *testing.T) {
+	stdout, _, err := Exec("sh", ExecOptions{Limits: Limits{CPUSeconds: 1}}, "-c", "echo partial; exit 3")
+
+	if _, isExitError := err.(*exec.ExitError); !isExitError {
+		t.Error("Found error", err, ", expected an exit error")
+	}
+
+	if stdout != "partial\n" {
+		t.Error("Found output", stdout, ", expected the output before the exit")
+	}
+}
+
+func TestKillRunning(t *testing.T) {
+	defer func() {
+		running.Lock()
+		running.killed = false
+		running.Unlock()
+	}()
+
+	done := make(chan error)
+	go func() {
+		_, _, err := Exec("sh", ExecOptions{}, "-c", "sleep 30")
+		done <- err
+	}()
+
+	// Wait for the process to start
+	for i := 0; i < 500; i++ {
+		running.Lock()
+		started := len(running.groups) > 0
+		running.Unlock()
+
+		if started {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	KillRunning()
+
+	select {
+	case err := <-done:
+		if _, isExitError := err.(*exec.ExitError); !isExitError {
+			t.Error("Found error", err, ", expected an exit error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The running process was not killed")
+	}
+
+	// Processes started afterwards are killed right away
+	if _, _, err := Exec("sh", ExecOptions{}, "-c", "sleep 30"); err == nil {
+		t.Error("Found a process started after KillRunning finishing correctly")
+	}
+}
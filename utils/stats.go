@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"math"
+)
+
+// TTest contains the result of a Student's t-test
+type TTest struct {
+	Mean   float64
+	StdErr float64
+	T      float64
+	// DegreesOfFreedom of the t distribution
+	DegreesOfFreedom int
+	// P is the two-sided p-value of the test
+	P float64
+}
+
+// Interval returns the confidence interval of the mean at the given level, e.g. 0.95
+func (test TTest) Interval(level float64) (float64, float64) {
+	if test.DegreesOfFreedom <= 0 {
+		return math.Inf(-1), math.Inf(1)
+	}
+
+	// Find the critical value by bisection on the two-sided p-value
+	low, high := 0.0, 1000.0
+	for i := 0; i < 100; i++ {
+		middle := (low + high) / 2
+
+		if StudentTTwoSided(middle, test.DegreesOfFreedom) > 1-level {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+
+	return test.Mean - high*test.StdErr, test.Mean + high*test.StdErr
+}
+
+// OneSampleTTest tests whether the mean of the values is different from
+// zero, which for paired differences is the paired t-test
+func OneSampleTTest(values []float64) TTest {
+	n := len(values)
+	test := TTest{DegreesOfFreedom: n - 1, P: 1}
+
+	if n == 0 {
+		return test
+	}
+
+	for _, value := range values {
+		test.Mean += value
+	}
+	test.Mean /= float64(n)
+
+	if n < 2 {
+		return test
+	}
+
+	variance := 0.0
+	for _, value := range values {
+		variance += (value - test.Mean) * (value - test.Mean)
+	}
+	variance /= float64(n - 1)
+
+	test.StdErr = math.Sqrt(variance / float64(n))
+
+	if test.StdErr == 0 {
+		if test.Mean != 0 {
+			test.T = math.Copysign(math.Inf(1), test.Mean)
+			test.P = 0
+		}
+
+		return test
+	}
+
+	test.T = test.Mean / test.StdErr
+	test.P = StudentTTwoSided(test.T, test.DegreesOfFreedom)
+
+	return test
+}
+
+// StudentTTwoSided returns the probability that the absolute value of a
+// variable with Student's t distribution is greater than |t|
+func StudentTTwoSided(t float64, degreesOfFreedom int) float64 {
+	df := float64(degreesOfFreedom)
+
+	return RegularizedIncompleteBeta(df/(df+t*t), df/2, 0.5)
+}
+
+// RegularizedIncompleteBeta returns I_x(a, b)
+func RegularizedIncompleteBeta(x float64, a float64, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+
+	if x >= 1 {
+		return 1
+	}
+
+	lbeta, _ := math.Lgamma(a + b)
+	lgammaA, _ := math.Lgamma(a)
+	lgammaB, _ := math.Lgamma(b)
+	front := math.Exp(lbeta - lgammaA - lgammaB + a*math.Log(x) + b*math.Log(1-x))
+
+	// The continued fraction converges quickly only for x < (a+1)/(a+b+2)
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(x, a, b) / a
+	}
+
+	return 1 - front*betaContinuedFraction(1-x, b, a)/b
+}
+
+// betaContinuedFraction evaluates the continued fraction of the incomplete
+// beta function with the modified Lentz's method
+func betaContinuedFraction(x float64, a float64, b float64) float64 {
+	const epsilon = 1e-14
+	const tiny = 1e-300
+
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	result := d
+
+	for m := 1.0; m <= 300; m++ {
+		// Even step
+		numerator := m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+
+		d = 1 + numerator*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + numerator/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		result *= d * c
+
+		// Odd step
+		numerator = -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+
+		d = 1 + numerator*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + numerator/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		result *= delta
+
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStudentTTwoSided(t *testing.T) {
+	tests := []struct {
+		T                float64
+		DegreesOfFreedom int
+		Expected         float64
+	}{
+		{0, 10, 1},
+		{2.228, 10, 0.05},
+		{1.96, 100000, 0.05},
+		{2.576, 100000, 0.01},
+		{12.706, 1, 0.05},
+	}
+
+	for _, test := range tests {
+		p := StudentTTwoSided(test.T, test.DegreesOfFreedom)
+
+		if math.Abs(p-test.Expected) > 1e-3 {
+			t.Error("Found p-value", p, "for t", test.T, ", expected", test.Expected)
+		}
+	}
+}
+
+func TestOneSampleTTest(t *testing.T) {
+	test := OneSampleTTest([]float64{1, 2, 3, 4, 5})
+
+	if test.Mean != 3 || test.DegreesOfFreedom != 4 || math.Abs(test.T-4.2426) > 1e-3 {
+		t.Error("Found", test)
+	}
+
+	low, high := test.Interval(0.95)
+
+	if math.Abs(low-1.0368) > 1e-3 || math.Abs(high-4.9632) > 1e-3 {
+		t.Error("Found interval", low, high, ", expected 1.0368 4.9632")
+	}
+}
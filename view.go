@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/jedib0t/go-pretty/text"
+)
+
+const replayFileName = "game.res"
+
+// ViewOptions ...
+type ViewOptions struct {
+	// Viewer is the folder that contains the game's HTML viewer
+	Viewer string
+	// ViewerFile is the HTML file of the viewer, relative to Viewer
+	ViewerFile string
+	// ViewerParam is the query parameter the viewer reads the replay URL from
+	ViewerParam string
+	Port        int
+	// Open opens the viewer URL in the default browser
+	Open bool
+}
+
+// replayFile returns the replay of a recorded game, or the replay of the last
+// game played with dojo run if id is empty
+func replayFile(id string) (string, error) {
+	if len(id) == 0 {
+		return "default.res", nil
+	}
+
+	gameResult, err := loadGameResult(id)
+	if err != nil {
+		return "", err
+	}
+
+	fileName := filepath.Join(gameDir(gameResult.ID), replayFileName)
+
+	if _, err := os.Stat(fileName); os.IsNotExist(err) {
+		return "", fmt.Errorf("the replay of game %s was not kept, rerun it with: dojo rerun %s", gameResult.ID, gameResult.ID)
+	}
+
+	return fileName, nil
+}
+
+func openBrowser(url string) {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	cmd.Start()
+}
+
+// View serves the viewer together with the replay until interrupted
+func View(replay string, options ViewOptions) error {
+	if _, err := os.Stat(replay); err != nil {
+		return fmt.Errorf("replay '%s' not found", replay)
+	}
+
+	if _, err := os.Stat(filepath.Join(options.Viewer, options.ViewerFile)); err != nil {
+		return fmt.Errorf("viewer '%s' not found, set the viewer folder with --viewer", filepath.Join(options.Viewer, options.ViewerFile))
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", http.FileServer(http.Dir(options.Viewer)))
+	mux.HandleFunc("/"+replayFileName, func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, replay)
+	})
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", options.Port))
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("http://%s/%s?%s=%s", listener.Addr(), options.ViewerFile, options.ViewerParam, replayFileName)
+
+	fmt.Printf("👀 Viewing %s at %s (press Ctrl+C to stop)\n", replay, text.Bold.Sprint(url))
+
+	if options.Open {
+		openBrowser(url)
+	}
+
+	return http.Serve(listener, mux)
+}